@@ -0,0 +1,77 @@
+package defs
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// evalArithmetic evaluates arithmetic expression consisting of numbers, parentheses and operators + - * /.
+func evalArithmetic(expression string) (float64, error) {
+	expr, err := parser.ParseExpr(expression)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse arithmetic expression '%s', err: %w", expression, err)
+	}
+
+	return evalArithmeticNode(expr)
+}
+
+// evalArithmeticNode evaluates parsed arithmetic expression.
+func evalArithmeticNode(node ast.Expr) (float64, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return 0, fmt.Errorf("unsupported literal %s, only numbers are allowed", n.Value)
+		}
+
+		return strconv.ParseFloat(n.Value, 64)
+	case *ast.ParenExpr:
+		return evalArithmeticNode(n.X)
+	case *ast.UnaryExpr:
+		x, err := evalArithmeticNode(n.X)
+		if err != nil {
+			return 0, err
+		}
+
+		switch n.Op {
+		case token.ADD:
+			return x, nil
+		case token.SUB:
+			return -x, nil
+		default:
+			return 0, fmt.Errorf("unsupported operator %s", n.Op)
+		}
+	case *ast.BinaryExpr:
+		x, err := evalArithmeticNode(n.X)
+		if err != nil {
+			return 0, err
+		}
+
+		y, err := evalArithmeticNode(n.Y)
+		if err != nil {
+			return 0, err
+		}
+
+		switch n.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			if y == 0 {
+				return 0, errors.New("division by zero")
+			}
+
+			return x / y, nil
+		default:
+			return 0, fmt.Errorf("unsupported operator %s", n.Op)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported expression of type %T, only numbers, parentheses and + - * / are allowed", node)
+	}
+}
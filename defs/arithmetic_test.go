@@ -0,0 +1,42 @@
+package defs
+
+import (
+	"testing"
+)
+
+func TestEvalArithmetic(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       float64
+		wantErr    bool
+	}{
+		{expression: "42", want: 42},
+		{expression: "1.5 + 2.25", want: 3.75},
+		{expression: "2 + 3 * 4", want: 14},
+		{expression: "(2 + 3) * 4", want: 20},
+		{expression: "10 - 4 - 3", want: 3},
+		{expression: "7 / 2", want: 3.5},
+		{expression: "-3 * +2", want: -6},
+		{expression: "-(1 - 5)", want: 4},
+		{expression: "1e3 / 10", want: 100},
+		{expression: "1 / 0", wantErr: true},
+		{expression: "2 % 3", wantErr: true},
+		{expression: `"a" + 1`, wantErr: true},
+		{expression: "x + 1", wantErr: true},
+		{expression: "!1", wantErr: true},
+		{expression: "1 +", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := evalArithmetic(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
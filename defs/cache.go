@@ -0,0 +1,66 @@
+package defs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pawelWritesCode/gdutils/pkg/cache"
+)
+
+// DeletableCache is entity that has ability to store, retrieve and delete arbitrary values.
+// It implements gdutils cache.Cache and is safe for concurrent use.
+type DeletableCache struct {
+	buff sync.Map
+}
+
+// NewDeletableCache returns *DeletableCache safe for concurrent use.
+func NewDeletableCache() *DeletableCache {
+	return &DeletableCache{}
+}
+
+// Save preserves provided value under given key.
+func (c *DeletableCache) Save(key string, value any) {
+	c.buff.Store(key, value)
+}
+
+// GetSaved retrieves value saved under given key.
+func (c *DeletableCache) GetSaved(key string) (any, error) {
+	val, ok := c.buff.Load(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", cache.ErrMissingKey, key)
+	}
+
+	return val, nil
+}
+
+// Delete removes value saved under given key, leaving other values untouched.
+func (c *DeletableCache) Delete(key string) error {
+	if _, ok := c.buff.LoadAndDelete(key); !ok {
+		return fmt.Errorf("%w: %s", cache.ErrMissingKey, key)
+	}
+
+	return nil
+}
+
+// Reset turns cache into init state - clears all entries.
+func (c *DeletableCache) Reset() {
+	c.buff.Range(func(key, _ any) bool {
+		c.buff.Delete(key)
+
+		return true
+	})
+}
+
+// All returns all cache entries.
+func (c *DeletableCache) All() map[string]any {
+	all := make(map[string]any)
+	c.buff.Range(func(key, value any) bool {
+		if keyStr, ok := key.(string); ok {
+			all[keyStr] = value
+		}
+
+		return true
+	})
+
+	return all
+}
@@ -0,0 +1,74 @@
+package defs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pawelWritesCode/gdutils/pkg/cache"
+)
+
+func TestDeletableCache_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+		wantAll map[string]any
+	}{
+		{name: "existing key", key: "a", wantAll: map[string]any{"b": 2}},
+		{name: "missing key", key: "c", wantErr: cache.ErrMissingKey, wantAll: map[string]any{"a": 1, "b": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDeletableCache()
+			c.Save("a", 1)
+			c.Save("b", 2)
+
+			if err := c.Delete(tt.key); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error: want %v, got %v", tt.wantErr, err)
+			}
+
+			if !reflect.DeepEqual(c.All(), tt.wantAll) {
+				t.Errorf("cache entries: want %v, got %v", tt.wantAll, c.All())
+			}
+		})
+	}
+}
+
+func TestDeletableCache_Reset(t *testing.T) {
+	c := NewDeletableCache()
+	c.Save("a", 1)
+	c.Reset()
+
+	if _, err := c.GetSaved("a"); !errors.Is(err, cache.ErrMissingKey) {
+		t.Errorf("reset cache should not have key 'a', err: %v", err)
+	}
+
+	if len(c.All()) != 0 {
+		t.Errorf("reset cache should be empty, got: %v", c.All())
+	}
+}
+
+func TestScenario_IRemoveCacheKey(t *testing.T) {
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("a", 1)
+	s.APIContext.Cache.Save("b", 2)
+
+	if err := s.IRemoveCacheKey("a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.IRemoveCacheKey("a"); err == nil {
+		t.Errorf("removing missing key should result in error")
+	}
+
+	if want := map[string]any{"b": 2}; !reflect.DeepEqual(s.APIContext.Cache.All(), want) {
+		t.Errorf("cache entries: want %v, got %v", want, s.APIContext.Cache.All())
+	}
+
+	s.APIContext.SetCache(cache.NewConcurrentCache())
+	if err := s.IRemoveCacheKey("b"); err == nil {
+		t.Errorf("cache not able to delete single key should result in error")
+	}
+}
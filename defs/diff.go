@@ -0,0 +1,274 @@
+package defs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// normalizeJSON returns data as it would be after JSON serialization and deserialization,
+// so values obtained by different PathFinders may be compared with each other.
+func normalizeJSON(data any) (any, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize value to JSON, err: %w", err)
+	}
+
+	var normalized any
+	if err = json.Unmarshal(b, &normalized); err != nil {
+		return nil, fmt.Errorf("could not deserialize value from JSON, err: %w", err)
+	}
+
+	return normalized, nil
+}
+
+// jsonDiff returns differences between expected and actual deserialized JSON values.
+// Each difference is described together with path at which it occurs, path of root is "$".
+func jsonDiff(path string, expected, actual any) []string {
+	switch exp := expected.(type) {
+	case map[string]any:
+		act, ok := actual.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object %s, got %s", path, toJSON(expected), toJSON(actual))}
+		}
+
+		var diffs []string
+		for _, key := range unionKeys(exp, act) {
+			expVal, expOk := exp[key]
+			actVal, actOk := act[key]
+			keyPath := path + "." + key
+
+			switch {
+			case !actOk:
+				diffs = append(diffs, fmt.Sprintf("%s: missing, expected %s", keyPath, toJSON(expVal)))
+			case !expOk:
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", keyPath, toJSON(actVal)))
+			default:
+				diffs = append(diffs, jsonDiff(keyPath, expVal, actVal)...)
+			}
+		}
+
+		return diffs
+	case []any:
+		act, ok := actual.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array %s, got %s", path, toJSON(expected), toJSON(actual))}
+		}
+
+		var diffs []string
+		if len(exp) != len(act) {
+			diffs = append(diffs, fmt.Sprintf("%s: expected array of length %d, got %d", path, len(exp), len(act)))
+		}
+
+		for i := 0; i < len(exp) && i < len(act); i++ {
+			diffs = append(diffs, jsonDiff(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i])...)
+		}
+
+		return diffs
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, toJSON(expected), toJSON(actual))}
+		}
+
+		return nil
+	}
+}
+
+// jsonSubsetMismatch returns first difference between expected and actual deserialized JSON values,
+// ignoring keys of actual objects that are not present in expected ones. Arrays are compared element by element,
+// so actual array may be longer than expected one. Path of root is "$". Empty string means that there is no difference.
+func jsonSubsetMismatch(path string, expected, actual any) string {
+	switch exp := expected.(type) {
+	case map[string]any:
+		act, ok := actual.(map[string]any)
+		if !ok {
+			return fmt.Sprintf("%s: expected object, got %s", path, toJSON(actual))
+		}
+
+		keys := make([]string, 0, len(exp))
+		for key := range exp {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := path + "." + key
+			actVal, ok := act[key]
+			if !ok {
+				return fmt.Sprintf("%s: missing, expected %s", keyPath, toJSON(exp[key]))
+			}
+
+			if mismatch := jsonSubsetMismatch(keyPath, exp[key], actVal); mismatch != "" {
+				return mismatch
+			}
+		}
+
+		return ""
+	case []any:
+		act, ok := actual.([]any)
+		if !ok {
+			return fmt.Sprintf("%s: expected array, got %s", path, toJSON(actual))
+		}
+
+		if len(act) < len(exp) {
+			return fmt.Sprintf("%s: expected array of length at least %d, got %d", path, len(exp), len(act))
+		}
+
+		for i := range exp {
+			if mismatch := jsonSubsetMismatch(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i]); mismatch != "" {
+				return mismatch
+			}
+		}
+
+		return ""
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Sprintf("%s: expected %s, got %s", path, toJSON(expected), toJSON(actual))
+		}
+
+		return ""
+	}
+}
+
+// jsonShapeMismatch returns first difference between shape and actual deserialized JSON value. Shape has the same
+// structure as expected value, but its leaves are type names: string, int, float, bool, map, slice, nil or any.
+// Shape array with single element describes every element of actual array, longer shape array is compared
+// element by element. Path of root is "$". Empty string means that there is no difference.
+func jsonShapeMismatch(path string, shape, actual any) (string, error) {
+	switch sh := shape.(type) {
+	case map[string]any:
+		act, ok := actual.(map[string]any)
+		if !ok {
+			return fmt.Sprintf("%s: expected map, got %s %s", path, goKind(actual), toJSON(actual)), nil
+		}
+
+		for _, key := range unionKeys(sh, act) {
+			keyPath := path + "." + key
+			shVal, shOk := sh[key]
+			actVal, actOk := act[key]
+			switch {
+			case !actOk:
+				return fmt.Sprintf("%s: missing, expected %s", keyPath, toJSON(shVal)), nil
+			case !shOk:
+				return fmt.Sprintf("%s: unexpected %s", keyPath, toJSON(actVal)), nil
+			}
+
+			if mismatch, err := jsonShapeMismatch(keyPath, shVal, actVal); mismatch != "" || err != nil {
+				return mismatch, err
+			}
+		}
+
+		return "", nil
+	case []any:
+		act, ok := actual.([]any)
+		if !ok {
+			return fmt.Sprintf("%s: expected slice, got %s %s", path, goKind(actual), toJSON(actual)), nil
+		}
+
+		if len(sh) != 1 && len(sh) != len(act) {
+			return fmt.Sprintf("%s: expected slice of length %d, got %d", path, len(sh), len(act)), nil
+		}
+
+		for i := range act {
+			elementShape := sh[0]
+			if len(sh) > 1 {
+				elementShape = sh[i]
+			}
+
+			if mismatch, err := jsonShapeMismatch(fmt.Sprintf("%s[%d]", path, i), elementShape, act[i]); mismatch != "" || err != nil {
+				return mismatch, err
+			}
+		}
+
+		return "", nil
+	case string:
+		kind := goKind(actual)
+		switch sh {
+		case "any":
+			return "", nil
+		case "string", "int", "float", "bool", "map", "slice", "nil":
+			if kind == sh || sh == "float" && kind == "int" {
+				return "", nil
+			}
+
+			return fmt.Sprintf("%s: expected %s, got %s %s", path, sh, kind, toJSON(actual)), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: shape leaf should be one of type names: string, int, float, bool, map, slice, nil, any, got: %s", path, toJSON(shape))
+}
+
+// unionKeys returns sorted keys present in any of provided maps.
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// jsonKind returns name of JSON type of deserialized JSON value.
+func jsonKind(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// goKind returns name of Go type of deserialized JSON value: string, int, float, bool, map, slice or nil.
+// JSON does not distinguish integers from floats, so every number without fractional part is int.
+func goKind(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "map"
+	case []any:
+		return "slice"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "int"
+		}
+
+		return "float"
+	case bool:
+		return "bool"
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// toJSON returns JSON representation of value or its Go representation if value can't be serialized.
+func toJSON(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,154 @@
+package defs
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// mustDeserializeJSON returns deserialized JSON data or fails test.
+func mustDeserializeJSON(t *testing.T, data string) any {
+	t.Helper()
+
+	var deserialized any
+	if err := json.Unmarshal([]byte(data), &deserialized); err != nil {
+		t.Fatalf("invalid JSON %s, err: %v", data, err)
+	}
+
+	return deserialized
+}
+
+func TestJSONDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     []string
+	}{
+		{name: "equal objects", expected: `{"a": 1, "b": [true, null]}`, actual: `{"b": [true, null], "a": 1.0}`},
+		{name: "different scalar", expected: `{"a": 1}`, actual: `{"a": "1"}`, want: []string{`$.a: expected 1, got "1"`}},
+		{
+			name:     "missing and unexpected keys",
+			expected: `{"a": 1, "b": 2}`,
+			actual:   `{"b": 2, "c": 3}`,
+			want:     []string{"$.a: missing, expected 1", "$.c: unexpected 3"},
+		},
+		{
+			name:     "nested difference",
+			expected: `{"user": {"tags": ["x", "y"]}}`,
+			actual:   `{"user": {"tags": ["x", "z"]}}`,
+			want:     []string{`$.user.tags[1]: expected "y", got "z"`},
+		},
+		{
+			name:     "array length",
+			expected: `[1, 2]`,
+			actual:   `[1, 3, 4]`,
+			want:     []string{"$: expected array of length 2, got 3", "$[1]: expected 2, got 3"},
+		},
+		{name: "object instead of array", expected: `{"a": []}`, actual: `{"a": {}}`, want: []string{"$.a: expected array [], got {}"}},
+		{name: "array instead of object", expected: `{}`, actual: `[]`, want: []string{"$: expected object {}, got []"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := jsonDiff("$", mustDeserializeJSON(t, tt.expected), mustDeserializeJSON(t, tt.actual))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONSubsetMismatch(t *testing.T) {
+	actual := `{"id": 1, "name": "x", "tags": ["a", "b", "c"], "owner": {"id": 2, "roles": ["admin"]}}`
+	tests := []struct {
+		name     string
+		expected string
+		want     string
+	}{
+		{name: "whole body", expected: actual},
+		{name: "subset of keys", expected: `{"name": "x", "owner": {"id": 2}}`},
+		{name: "prefix of array", expected: `{"tags": ["a", "b"]}`},
+		{name: "empty object", expected: `{}`},
+		{name: "different value", expected: `{"owner": {"id": 3}}`, want: "$.owner.id: expected 3, got 2"},
+		{name: "missing key", expected: `{"email": "x@example.com"}`, want: `$.email: missing, expected "x@example.com"`},
+		{name: "different array element", expected: `{"tags": ["b"]}`, want: `$.tags[0]: expected "b", got "a"`},
+		{name: "too long array", expected: `{"owner": {"roles": ["admin", "user"]}}`, want: "$.owner.roles: expected array of length at least 2, got 1"},
+		{name: "object instead of scalar", expected: `{"name": {}}`, want: `$.name: expected object, got "x"`},
+		{name: "array instead of scalar", expected: `{"id": []}`, want: "$.id: expected array, got 1"},
+		{name: "first difference in key order", expected: `{"b": 1, "a": 1}`, want: "$.a: missing, expected 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := jsonSubsetMismatch("$", mustDeserializeJSON(t, tt.expected), mustDeserializeJSON(t, actual))
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONShapeMismatch(t *testing.T) {
+	actual := `{"id": 1, "price": 9.99, "name": "x", "active": true, "tags": ["a", "b"], "owner": {"id": 2}, "deleted": null}`
+	tests := []struct {
+		name    string
+		shape   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "matching shape",
+			shape: `{"id": "int", "price": "float", "name": "string", "active": "bool", "tags": ["string"], "owner": {"id": "int"}, "deleted": "nil"}`,
+		},
+		{
+			name:  "int is float and any matches everything",
+			shape: `{"id": "float", "price": "any", "name": "any", "active": "any", "tags": "slice", "owner": "map", "deleted": "any"}`,
+		},
+		{
+			name:  "element by element slice",
+			shape: `{"id": "int", "price": "float", "name": "string", "active": "bool", "tags": ["string", "string"], "owner": {"id": "int"}, "deleted": "nil"}`,
+		},
+		{
+			name:  "float is not int",
+			shape: `{"id": "int", "price": "int", "name": "string", "active": "bool", "tags": ["string"], "owner": {"id": "int"}, "deleted": "nil"}`,
+			want:  "$.price: expected int, got float 9.99",
+		},
+		{
+			name:  "missing key",
+			shape: `{"email": "string", "id": "int", "price": "float", "name": "string", "active": "bool", "tags": ["string"], "owner": {"id": "int"}, "deleted": "nil"}`,
+			want:  `$.email: missing, expected "string"`,
+		},
+		{
+			name:  "unexpected key",
+			shape: `{"id": "int", "price": "float", "name": "string", "active": "bool", "tags": ["string"], "owner": {}, "deleted": "nil"}`,
+			want:  "$.owner.id: unexpected 2",
+		},
+		{
+			name:  "wrong slice element",
+			shape: `{"id": "int", "price": "float", "name": "string", "active": "bool", "tags": ["int"], "owner": {"id": "int"}, "deleted": "nil"}`,
+			want:  `$.tags[0]: expected int, got string "a"`,
+		},
+		{
+			name:  "wrong slice length",
+			shape: `{"id": "int", "price": "float", "name": "string", "active": "bool", "tags": ["string", "string", "string"], "owner": {"id": "int"}, "deleted": "nil"}`,
+			want:  "$.tags: expected slice of length 3, got 2",
+		},
+		{name: "map instead of slice", shape: `[]`, want: `$: expected slice, got map ` + `{"active":true,"deleted":null,"id":1,"name":"x","owner":{"id":2},"price":9.99,"tags":["a","b"]}`},
+		{name: "unknown type name", shape: `{"active": "bool", "deleted": "nil", "id": "integer"}`, wantErr: true},
+		{name: "leaf that is not type name", shape: `{"active": "bool", "deleted": "nil", "id": 1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonShapeMismatch("$", mustDeserializeJSON(t, tt.shape), mustDeserializeJSON(t, actual))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package defs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// voidHTMLElements are elements that can't have any content, so they don't have end tags.
+var voidHTMLElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// optionalEndTagHTMLElements are elements which end tags may be omitted according to HTML specification.
+var optionalEndTagHTMLElements = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true, "dt": true, "dd": true,
+	"option": true, "optgroup": true, "colgroup": true, "caption": true, "thead": true, "tbody": true,
+	"tfoot": true, "tr": true, "td": true, "th": true, "rb": true, "rt": true, "rtc": true, "rp": true,
+}
+
+// htmlIssues returns problems with structure of HTML document, such as unexpected end tags
+// or elements that were not closed. Elements which end tags are optional are not reported.
+func htmlIssues(data []byte) ([]string, error) {
+	var issues []string
+	var open []string
+	line := 1
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			if err := tokenizer.Err(); !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("could not tokenize HTML, err: %w", err)
+			}
+
+			break
+		}
+
+		tokenLine := line
+		line += bytes.Count(tokenizer.Raw(), []byte("\n"))
+
+		name, _ := tokenizer.TagName()
+		tag := strings.ToLower(string(name))
+		switch tokenType {
+		case html.StartTagToken:
+			if !voidHTMLElements[tag] {
+				open = append(open, tag)
+			}
+		case html.EndTagToken:
+			if voidHTMLElements[tag] {
+				issues = append(issues, fmt.Sprintf("line %d: end tag </%s> of void element", tokenLine, tag))
+
+				continue
+			}
+
+			i := len(open) - 1
+			for ; i >= 0 && open[i] != tag; i-- {
+			}
+
+			if i < 0 {
+				issues = append(issues, fmt.Sprintf("line %d: unexpected end tag </%s>", tokenLine, tag))
+
+				continue
+			}
+
+			for _, unclosed := range open[i+1:] {
+				if !optionalEndTagHTMLElements[unclosed] {
+					issues = append(issues, fmt.Sprintf("line %d: element <%s> not closed before </%s>", tokenLine, unclosed, tag))
+				}
+			}
+
+			open = open[:i]
+		}
+	}
+
+	for _, unclosed := range open {
+		if !optionalEndTagHTMLElements[unclosed] {
+			issues = append(issues, fmt.Sprintf("element <%s> is not closed", unclosed))
+		}
+	}
+
+	return issues, nil
+}
+
+// htmlHasContent checks whether parsed HTML document has any element or text,
+// besides html, head and body elements added implicitly by parser.
+func htmlHasContent(n *html.Node) bool {
+	switch n.Type {
+	case html.ElementNode:
+		if n.Data != "html" && n.Data != "head" && n.Data != "body" {
+			return true
+		}
+	case html.TextNode:
+		if strings.TrimSpace(n.Data) != "" {
+			return true
+		}
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if htmlHasContent(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// htmlText returns concatenated content of all text nodes of HTML node and its descendants.
+func htmlText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(htmlText(child))
+	}
+
+	return sb.String()
+}
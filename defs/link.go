@@ -0,0 +1,59 @@
+package defs
+
+import (
+	"strings"
+)
+
+// linkRelations parses RFC 8288 Link header values and returns URLs of found relations in order of appearance.
+// Link may have many space-separated relations, if relation appears more than once, first URL wins.
+func linkRelations(values []string) (rels []string, urls map[string]string) {
+	urls = map[string]string{}
+	for _, value := range values {
+		for _, link := range splitLinks(value) {
+			start, end := strings.Index(link, "<"), strings.Index(link, ">")
+			if start < 0 || end < start {
+				continue
+			}
+
+			target := strings.TrimSpace(link[start+1 : end])
+			for _, param := range strings.Split(link[end+1:], ";") {
+				name, val, found := strings.Cut(param, "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					rel = strings.ToLower(rel)
+					if _, ok := urls[rel]; !ok {
+						rels = append(rels, rel)
+						urls[rel] = target
+					}
+				}
+			}
+		}
+	}
+
+	return rels, urls
+}
+
+// splitLinks splits Link header value into separate links, ignoring commas inside URLs and quoted strings.
+func splitLinks(value string) []string {
+	var links []string
+	var inURL, inQuotes bool
+	start := 0
+	for i, r := range value {
+		switch {
+		case r == '<' && !inQuotes:
+			inURL = true
+		case r == '>' && !inQuotes:
+			inURL = false
+		case r == '"' && !inURL:
+			inQuotes = !inQuotes
+		case r == ',' && !inURL && !inQuotes:
+			links = append(links, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(links, value[start:])
+}
@@ -0,0 +1,80 @@
+package defs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkRelations(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		wantRels []string
+		wantURLs map[string]string
+	}{
+		{
+			name:     "single link",
+			values:   []string{`<https://api.example.com/users?page=2>; rel="next"`},
+			wantRels: []string{"next"},
+			wantURLs: map[string]string{"next": "https://api.example.com/users?page=2"},
+		},
+		{
+			name:     "many links in one value",
+			values:   []string{`</users?page=3>; rel="next", </users?page=1>; rel="prev"`},
+			wantRels: []string{"next", "prev"},
+			wantURLs: map[string]string{"next": "/users?page=3", "prev": "/users?page=1"},
+		},
+		{
+			name:     "many header values",
+			values:   []string{`</users?page=3>; rel=next`, `</users?page=9>; rel=last`},
+			wantRels: []string{"next", "last"},
+			wantURLs: map[string]string{"next": "/users?page=3", "last": "/users?page=9"},
+		},
+		{
+			name:     "space separated relations",
+			values:   []string{`</users?page=1>; rel="first prev"`},
+			wantRels: []string{"first", "prev"},
+			wantURLs: map[string]string{"first": "/users?page=1", "prev": "/users?page=1"},
+		},
+		{
+			name:     "commas inside URL and quoted params",
+			values:   []string{`</users?ids=1,2>; title="a, b"; rel="next", </users?ids=0>; rel="prev"`},
+			wantRels: []string{"next", "prev"},
+			wantURLs: map[string]string{"next": "/users?ids=1,2", "prev": "/users?ids=0"},
+		},
+		{
+			name:     "relation case and parameter order",
+			values:   []string{`</users?page=2>; type="application/json"; REL="Next"`},
+			wantRels: []string{"next"},
+			wantURLs: map[string]string{"next": "/users?page=2"},
+		},
+		{
+			name:     "first URL of repeated relation wins",
+			values:   []string{`</a>; rel="next", </b>; rel="next"`},
+			wantRels: []string{"next"},
+			wantURLs: map[string]string{"next": "/a"},
+		},
+		{
+			name:     "malformed links are skipped",
+			values:   []string{`users?page=2; rel="next", </users?page=1>; title="no rel"`},
+			wantURLs: map[string]string{},
+		},
+		{
+			name:     "no values",
+			wantURLs: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rels, urls := linkRelations(tt.values)
+			if !reflect.DeepEqual(rels, tt.wantRels) {
+				t.Errorf("relations: want %v, got %v", tt.wantRels, rels)
+			}
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("URLs: want %v, got %v", tt.wantURLs, urls)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package defs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unixTimestampThreshold separates rate limit reset values given as delta seconds from Unix timestamps.
+// Values lower than it (roughly one year in seconds) are treated as number of seconds from now.
+const unixTimestampThreshold = 365 * 24 * 60 * 60
+
+// rateLimitHeaders returns names of rate limit headers present in header: Retry-After,
+// X-RateLimit-* and RateLimit-* (IETF draft), sorted alphabetically.
+func rateLimitHeaders(header http.Header) []string {
+	var names []string
+	for name := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if canonical == "Retry-After" || canonical == "Ratelimit" || strings.HasPrefix(canonical, "X-Ratelimit-") ||
+			strings.HasPrefix(canonical, "Ratelimit-") {
+			names = append(names, canonical)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// rateLimitReset returns time when rate limit resets, obtained from first available header:
+// Retry-After (delta seconds or HTTP-date), X-RateLimit-Reset or RateLimit-Reset
+// (delta seconds or Unix timestamp in seconds).
+func rateLimitReset(header http.Header, now time.Time) (time.Time, error) {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), nil
+		}
+
+		date, err := http.ParseTime(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("header 'Retry-After' should be number of seconds or HTTP-date, got: '%s'", value)
+		}
+
+		return date, nil
+	}
+
+	for _, name := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("header '%s' should be number of seconds or Unix timestamp, got: '%s'", name, value)
+		}
+
+		if seconds < unixTimestampThreshold {
+			return now.Add(time.Duration(seconds) * time.Second), nil
+		}
+
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Time{}, errors.New("none of headers 'Retry-After', 'X-RateLimit-Reset', 'RateLimit-Reset' is present")
+}
@@ -0,0 +1,87 @@
+package defs
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   []string
+	}{
+		{name: "no headers", header: http.Header{"Content-Type": {"application/json"}}},
+		{
+			name: "all kinds of headers",
+			header: http.Header{
+				"Retry-After":           {"30"},
+				"X-Ratelimit-Remaining": {"0"},
+				"Ratelimit-Limit":       {"100"},
+				"Ratelimit":             {"limit=100, remaining=0, reset=30"},
+				"Content-Type":          {"application/json"},
+			},
+			want: []string{"Ratelimit", "Ratelimit-Limit", "Retry-After", "X-Ratelimit-Remaining"},
+		},
+		{
+			name:   "not canonical names",
+			header: http.Header{"x-ratelimit-reset": {"30"}, "X-Ratelimited": {"yes"}},
+			want:   []string{"X-Ratelimit-Reset"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rateLimitHeaders(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		header  http.Header
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "Retry-After seconds", header: http.Header{"Retry-After": {"120"}}, want: now.Add(2 * time.Minute)},
+		{
+			name:   "Retry-After HTTP-date",
+			header: http.Header{"Retry-After": {"Wed, 01 Mar 2023 12:05:00 GMT"}},
+			want:   time.Date(2023, 3, 1, 12, 5, 0, 0, time.UTC),
+		},
+		{name: "Retry-After invalid", header: http.Header{"Retry-After": {"soon"}}, wantErr: true},
+		{
+			name:   "Retry-After takes precedence",
+			header: http.Header{"Retry-After": {"10"}, "X-Ratelimit-Reset": {"60"}},
+			want:   now.Add(10 * time.Second),
+		},
+		{name: "X-RateLimit-Reset delta seconds", header: http.Header{"X-Ratelimit-Reset": {"60"}}, want: now.Add(time.Minute)},
+		{
+			name:   "X-RateLimit-Reset Unix timestamp",
+			header: http.Header{"X-Ratelimit-Reset": {"1677672300"}},
+			want:   time.Unix(1677672300, 0),
+		},
+		{name: "RateLimit-Reset delta seconds", header: http.Header{"Ratelimit-Reset": {"5"}}, want: now.Add(5 * time.Second)},
+		{name: "X-RateLimit-Reset invalid", header: http.Header{"X-Ratelimit-Reset": {"1.5"}}, wantErr: true},
+		{name: "missing headers", header: http.Header{"X-Ratelimit-Remaining": {"0"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rateLimitReset(tt.header, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
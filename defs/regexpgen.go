@@ -0,0 +1,122 @@
+package defs
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxUnboundedRepeat is number of repetitions added at most to minimum of unbounded quantifiers like * or +.
+const maxUnboundedRepeat = 10
+
+// generateStringMatchingRegExp returns random string matching provided regExp pattern.
+// Supported are literals, char classes, any char, groups, alternations, quantifiers and anchors.
+// Other constructs, for example word boundaries, result in error.
+func generateStringMatchingRegExp(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("could not parse regExp '%s', err: %w", pattern, err)
+	}
+
+	var sb strings.Builder
+	if err = generateFromRegExp(&sb, re); err != nil {
+		return "", fmt.Errorf("could not generate string matching regExp '%s', err: %w", pattern, err)
+	}
+
+	return sb.String(), nil
+}
+
+// generateFromRegExp writes to sb random string matching parsed regExp.
+func generateFromRegExp(sb *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return nil
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		r, err := randomRuneFromClass(re.Rune)
+		if err != nil {
+			return err
+		}
+
+		sb.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune(' ' + rand.Intn('~'-' '+1)))
+	case syntax.OpCapture:
+		return generateFromRegExp(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := generateFromRegExp(sb, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return generateFromRegExp(sb, re.Sub[rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatRange(re)
+		for i := min + rand.Intn(max-min+1); i > 0; i-- {
+			if err := generateFromRegExp(sb, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported construct '%s'", re)
+	}
+
+	return nil
+}
+
+// repeatRange returns minimum and maximum number of repetitions of quantified regExp.
+func repeatRange(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxUnboundedRepeat
+	case syntax.OpPlus:
+		return 1, 1 + maxUnboundedRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	default:
+		if re.Max < 0 {
+			return re.Min, re.Min + maxUnboundedRepeat
+		}
+
+		return re.Min, re.Max
+	}
+}
+
+// randomRuneFromClass returns random rune from char class described by pairs of inclusive ranges.
+// Printable ASCII runes are preferred, so negated classes don't produce exotic characters.
+func randomRuneFromClass(ranges []rune) (rune, error) {
+	var printable []rune
+	var total int
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r <= '~'; r++ {
+			if r >= ' ' {
+				printable = append(printable, r)
+			}
+		}
+
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+
+	if len(printable) > 0 {
+		return printable[rand.Intn(len(printable))], nil
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("empty char class")
+	}
+
+	pick := rand.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if pick < size {
+			return ranges[i] + rune(pick), nil
+		}
+
+		pick -= size
+	}
+
+	return ranges[0], nil
+}
@@ -0,0 +1,66 @@
+package defs
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateStringMatchingRegExp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: `abc`},
+		{pattern: `^[a-z]{5}$`},
+		{pattern: `^[A-Z][a-z]+-\d{2,4}$`},
+		{pattern: `^(foo|bar|baz)?x*$`},
+		{pattern: `^[^a-z0-9]{3}$`},
+		{pattern: `^.{1,}\.com$`},
+		{pattern: `^\w+@\w+\.(pl|com)$`},
+		{pattern: `^[ąęł]{2}$`},
+		{pattern: `\bword\b`, wantErr: true},
+		{pattern: `[a-`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			// generation is random, so every pattern is checked many times
+			for i := 0; i < 100; i++ {
+				generated, err := generateStringMatchingRegExp(tt.pattern)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if tt.wantErr {
+					return
+				}
+
+				if !regexp.MustCompile(tt.pattern).MatchString(generated) {
+					t.Fatalf("generated string '%s' does not match regExp '%s'", generated, tt.pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestScenario_IGenerateStringMatchingRegExpAndSaveItAs(t *testing.T) {
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("DIGIT", "[0-9]")
+
+	if err := s.IGenerateStringMatchingRegExpAndSaveItAs(`^{{.DIGIT}}{6}$`, "GENERATED"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	generated, err := s.APIContext.Cache.GetSaved("GENERATED")
+	if err != nil {
+		t.Fatalf("generated string should be saved, err: %v", err)
+	}
+
+	if !regexp.MustCompile(`^[0-9]{6}$`).MatchString(generated.(string)) {
+		t.Errorf("generated string '%v' should consist of 6 digits", generated)
+	}
+
+	if err = s.IGenerateStringMatchingRegExpAndSaveItAs(`(?=lookahead)`, "GENERATED"); err == nil {
+		t.Errorf("invalid regExp should result in error")
+	}
+}
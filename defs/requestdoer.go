@@ -0,0 +1,196 @@
+package defs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pawelWritesCode/gdutils"
+	"github.com/pawelWritesCode/gdutils/pkg/cache"
+	"github.com/pawelWritesCode/gdutils/pkg/httpcache"
+	"github.com/pawelWritesCode/gdutils/pkg/httpctx"
+)
+
+// RequestTrace contains details about HTTP(s) requests sent by TracingRequestDoer.
+type RequestTrace struct {
+	// LastRequest is last sent HTTP(s) request. Its body is already consumed, use LastRequestBody instead.
+	LastRequest *http.Request
+
+	// LastRequestBody is body of last sent HTTP(s) request.
+	LastRequestBody []byte
+
+	// TimeToFirstByte is time between sending last HTTP(s) request and receiving first byte of its response.
+	TimeToFirstByte time.Duration
+
+	// DNSLookupTime is DNS lookup time of last HTTP(s) request.
+	// Zero means that no DNS lookup was performed, for example because connection was reused.
+	DNSLookupTime time.Duration
+
+	// ConnectTime is time of establishing connection for last HTTP(s) request.
+	// Zero means that no new connection was established, for example because connection was reused.
+	ConnectTime time.Duration
+
+	// ConnectionReused tells whether last HTTP(s) request was sent over reused connection,
+	// for example kept alive from previous request.
+	ConnectionReused bool
+
+	// RequestsSent is number of all sent HTTP(s) requests.
+	RequestsSent int
+}
+
+// TracingRequestDoer is entity that sends HTTP(s) requests and collects details about them,
+// such as sent request, its body or timings collected during sending. Details are kept by TracingRequestDoer
+// itself, not in scenario cache, so they don't mix with values saved by user. It should be created for each scenario.
+type TracingRequestDoer struct {
+	// RequestDoer is service that has ability to send HTTP(s) requests.
+	RequestDoer httpctx.RequestDoer
+
+	// Cache is scenario cache. Last response saved in it is released before next request is sent.
+	Cache cache.Cache
+
+	// Logger logs sent HTTP(s) requests, if structured request logging is enabled.
+	Logger RequestLogger
+
+	mu             sync.Mutex
+	trace          RequestTrace
+	loggingEnabled bool
+}
+
+// NewTracingRequestDoer returns *TracingRequestDoer, which logs requests as JSON lines to standard error output.
+func NewTracingRequestDoer(r httpctx.RequestDoer, c cache.Cache) *TracingRequestDoer {
+	return &TracingRequestDoer{RequestDoer: r, Cache: c, Logger: NewJSONRequestLogger(os.Stderr)}
+}
+
+// Trace returns details about HTTP(s) requests sent so far.
+func (t *TracingRequestDoer) Trace() RequestTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.trace
+}
+
+// SetLoggingEnabled turns on or off logging of following HTTP(s) requests by Logger.
+func (t *TracingRequestDoer) SetLoggingEnabled(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.loggingEnabled = enabled
+}
+
+// Do sends HTTP(s) request and collects details about it.
+func (t *TracingRequestDoer) Do(req *http.Request) (*http.Response, error) {
+	body := []byte{}
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("could not read request body, err: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	t.releaseLastResponse()
+
+	t.mu.Lock()
+	t.trace = RequestTrace{LastRequest: req, LastRequestBody: body, RequestsSent: t.trace.RequestsSent + 1}
+	t.mu.Unlock()
+
+	var firstByte, dnsStart, dnsDone, connectStart, connectDone time.Time
+	var connReused bool
+
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		GotConn:              func(info httptrace.GotConnInfo) { connReused = info.Reused },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+
+	start := time.Now()
+	resp, err := t.RequestDoer.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	t.log(req, resp, err, start)
+	if err != nil {
+		return resp, err
+	}
+
+	t.mu.Lock()
+	t.trace.TimeToFirstByte = firstByte.Sub(start)
+	t.trace.DNSLookupTime = phaseDuration(dnsStart, dnsDone)
+	t.trace.ConnectTime = phaseDuration(connectStart, connectDone)
+	t.trace.ConnectionReused = connReused
+	t.mu.Unlock()
+
+	return resp, nil
+}
+
+// releaseLastResponse reads and closes body of last HTTP(s) response, if it was not read yet, so its connection
+// may be reused by next request. Body is buffered, so it still may be read again.
+func (t *TracingRequestDoer) releaseLastResponse() {
+	saved, err := t.Cache.GetSaved(httpcache.LastHTTPResponseCacheKey)
+	if err != nil {
+		return
+	}
+
+	resp, ok := saved.(*http.Response)
+	if !ok || resp == nil || resp.Body == nil {
+		return
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+// log passes details about sent HTTP(s) request to Logger, if structured request logging is enabled.
+func (t *TracingRequestDoer) log(req *http.Request, resp *http.Response, err error, start time.Time) {
+	t.mu.Lock()
+	enabled := t.loggingEnabled
+	t.mu.Unlock()
+
+	if !enabled || t.Logger == nil {
+		return
+	}
+
+	entry := RequestLogEntry{
+		Time:       start,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if resp != nil {
+		entry.Status = resp.StatusCode
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	t.Logger.LogRequest(entry)
+}
+
+// NewHTTPClient returns HTTP client with gdutils default transport settings,
+// that is additionally able to send requests to Unix domain sockets.
+func NewHTTPClient() *http.Client {
+	return newHTTPClient(defaultTransport())
+}
+
+// newHTTPClient returns HTTP client using provided transport, that sets User-Agent header of every request.
+func newHTTPClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: &gdutils.CustomTransport{RoundTripper: transport}}
+}
+
+// phaseDuration returns duration of traced phase or zero, if phase did not occur.
+func phaseDuration(start, done time.Time) time.Duration {
+	if start.IsZero() || done.IsZero() {
+		return 0
+	}
+
+	return done.Sub(start)
+}
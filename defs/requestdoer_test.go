@@ -0,0 +1,259 @@
+package defs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cucumber/godog"
+	"github.com/pawelWritesCode/gdutils/pkg/httpcache"
+)
+
+func TestTracingRequestDoer_StructuredRequestLogging(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	s := newTestScenario(t)
+	s.APIContext.RequestDoer.(*TracingRequestDoer).Logger = NewJSONRequestLogger(&buf)
+
+	sendGetRequest(t, s, srv.URL+"/before")
+	if err := s.IEnableStructuredRequestLogging(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL+"/users")
+	prepareRequest(t, s, http.MethodPost, srv.URL+"/users", "POST_REQUEST")
+	if err := s.ISendRequest("POST_REQUEST"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	if err := s.IDisableStructuredRequestLogging(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL+"/after")
+
+	expected := []struct {
+		method string
+		url    string
+		status float64
+	}{
+		{method: http.MethodGet, url: srv.URL + "/users", status: http.StatusOK},
+		{method: http.MethodPost, url: srv.URL + "/users", status: http.StatusCreated},
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	for i, want := range expected {
+		if !scanner.Scan() {
+			t.Fatalf("request %d should be logged, log: %s", i, buf.String())
+		}
+
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("log line %d should be valid JSON, got: %s, err: %v", i, scanner.Text(), err)
+		}
+
+		if entry["method"] != want.method || entry["url"] != want.url || entry["status"] != want.status {
+			t.Errorf("log line %d: want method %s, url %s, status %v, got: %s", i, want.method, want.url, want.status, scanner.Text())
+		}
+
+		if duration, ok := entry["duration_ms"].(float64); !ok || duration < 0 {
+			t.Errorf("log line %d should have non-negative duration_ms, got: %s", i, scanner.Text())
+		}
+	}
+
+	if scanner.Scan() {
+		t.Errorf("only requests sent while logging is enabled should be logged, got extra line: %s", scanner.Text())
+	}
+}
+
+func TestScenario_ISetStructuredLogging(t *testing.T) {
+	s := newTestScenario(t)
+	s.APIContext.SetRequestDoer(NewHTTPClient())
+
+	if err := s.ISetStructuredLogging(true); err == nil {
+		t.Errorf("logging should not be enabled without TracingRequestDoer")
+	}
+
+	if err := s.ISetStructuredLogging(false); err != nil {
+		t.Errorf("logging should be possible to disable, err: %v", err)
+	}
+}
+
+func TestScenario_LastRequestShouldHaveReusedConnection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	if err := s.LastRequestShouldHaveReusedConnection(); err == nil {
+		t.Errorf("reuse should not be asserted before any request was sent")
+	}
+
+	sendGetRequest(t, s, srv.URL)
+	if err := s.LastRequestShouldHaveReusedConnection(); err == nil {
+		t.Errorf("first request should establish new connection")
+	}
+
+	sendGetRequest(t, s, srv.URL)
+	if err := s.LastRequestShouldHaveReusedConnection(); err != nil {
+		t.Errorf("second request should reuse connection, err: %v", err)
+	}
+
+	srv.CloseClientConnections()
+	sendGetRequest(t, s, srv.URL)
+	if err := s.LastRequestShouldHaveReusedConnection(); err == nil {
+		t.Errorf("request sent after server closed connections should establish new connection")
+	}
+}
+
+func TestTracingRequestDoer_Trace(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	tracing := s.APIContext.RequestDoer.(*TracingRequestDoer)
+	if trace := tracing.Trace(); trace.RequestsSent != 0 || trace.LastRequest != nil {
+		t.Errorf("trace should be empty before any request is sent, got: %+v", trace)
+	}
+
+	prepareRequest(t, s, http.MethodPost, srv.URL+"/users", "POST_REQUEST")
+	if err := s.ISetFollowingBodyForPreparedRequest("POST_REQUEST", &godog.DocString{Content: `{"name": "x"}`}); err != nil {
+		t.Fatalf("could not set body, err: %v", err)
+	}
+
+	if err := s.ISendRequest("POST_REQUEST"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	// changing HTTP client keeps details about already sent requests
+	if err := s.IForceHTTP1(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL+"/users")
+	sendGetRequest(t, s, srv.URL+"/users")
+
+	trace := s.APIContext.RequestDoer.(*TracingRequestDoer).Trace()
+	if trace.RequestsSent != 3 {
+		t.Errorf("number of sent requests: want 3, got %d", trace.RequestsSent)
+	}
+
+	if trace.LastRequest == nil || trace.LastRequest.Method != http.MethodGet || len(trace.LastRequestBody) != 0 {
+		t.Errorf("last request should be GET without body, got: %+v", trace)
+	}
+
+	if !trace.ConnectionReused || trace.ConnectTime != 0 || trace.TimeToFirstByte <= 0 {
+		t.Errorf("second request should reuse connection and have time to first byte, got: %+v", trace)
+	}
+
+	// scenario cache holds only values saved by steps, such as prepared requests and last response
+	allowed := map[string]bool{
+		"POST_REQUEST":                      true,
+		"GET_REQUEST":                       true,
+		httpcache.LastHTTPResponseCacheKey:  true,
+		httpcache.LastHTTPRequestTimestamp:  true,
+		httpcache.LastHTTPResponseTimestamp: true,
+	}
+
+	for key := range s.APIContext.Cache.All() {
+		if !allowed[key] {
+			t.Errorf("scenario cache should not contain details collected by tracing, got key: %s", key)
+		}
+	}
+}
+
+func TestScenario_TimeToFirstByteShouldBeLessThan(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/slow-body" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/slow-headers", wantErr: true},
+		{path: "/slow-body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			s := newTestScenario(t)
+			if err := s.TimeToFirstByteShouldBeLessThan("100ms"); err == nil {
+				t.Errorf("time to first byte should not be known before any request is sent")
+			}
+
+			sendGetRequest(t, s, srv.URL+tt.path)
+			if err := s.TimeToFirstByteShouldBeLessThan("100ms"); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := s.TimeToFirstByteShouldBeLessThan("1s"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_DNSLookupAndConnectTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	// host name instead of IP address makes client look it up
+	srvURL := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	s := newTestScenario(t)
+	if err := s.DNSLookupTimeShouldBeLessThan("1s"); err == nil {
+		t.Errorf("DNS lookup time should not be known before any request is sent")
+	}
+
+	sendGetRequest(t, s, srvURL)
+	trace := s.APIContext.RequestDoer.(*TracingRequestDoer).Trace()
+	if trace.DNSLookupTime <= 0 || trace.ConnectTime <= 0 {
+		t.Errorf("DNS lookup and connect time of new connection should be recorded, got: %+v", trace)
+	}
+
+	if err := s.DNSLookupTimeShouldBeLessThan("1s"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := s.ConnectTimeShouldBeLessThan("1s"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := s.ConnectTimeShouldBeLessThan("1ns"); err == nil {
+		t.Errorf("connect time should not be less than 1ns")
+	}
+
+	// reused connection requires neither DNS lookup nor connecting
+	sendGetRequest(t, s, srvURL)
+	if trace = s.APIContext.RequestDoer.(*TracingRequestDoer).Trace(); trace.DNSLookupTime != 0 || trace.ConnectTime != 0 {
+		t.Errorf("DNS lookup and connect time of reused connection should be zero, got: %+v", trace)
+	}
+}
@@ -0,0 +1,48 @@
+package defs
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// RequestLogEntry describes single sent HTTP(s) request together with its outcome.
+type RequestLogEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Status     int       `json:"status,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RequestLogger is entity that has ability to log sent HTTP(s) requests.
+type RequestLogger interface {
+	// LogRequest logs details about sent HTTP(s) request.
+	LogRequest(entry RequestLogEntry)
+}
+
+// JSONRequestLogger is entity that logs every HTTP(s) request as single line of JSON, which is easy to parse by CI tools.
+type JSONRequestLogger struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewJSONRequestLogger returns *JSONRequestLogger writing log lines to w.
+func NewJSONRequestLogger(w io.Writer) *JSONRequestLogger {
+	return &JSONRequestLogger{writer: w}
+}
+
+// LogRequest writes entry as single line of JSON.
+func (l *JSONRequestLogger) LogRequest(entry RequestLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, _ = l.writer.Write(append(line, '\n'))
+}
@@ -0,0 +1,17 @@
+package defs
+
+import "net/http"
+
+// SavedResponse is snapshot of HTTP(s) response, that may be saved in cache.
+// Its fields are accessible through template syntax, for example: {{.RESPONSE.StatusCode}}
+// or {{.RESPONSE.Headers.Get "Content-Type"}}.
+type SavedResponse struct {
+	// StatusCode is HTTP(s) response status code.
+	StatusCode int
+
+	// Headers are HTTP(s) response headers.
+	Headers http.Header
+
+	// Body is HTTP(s) response body.
+	Body string
+}
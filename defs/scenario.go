@@ -1,51 +1,95 @@
 package defs
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/andybalholm/cascadia"
 	"github.com/cucumber/godog"
+	"github.com/goccy/go-yaml"
+	"github.com/gofrs/uuid"
+	"github.com/oliveagle/jsonpath"
 	ch "github.com/pawelWritesCode/charset"
 	"github.com/pawelWritesCode/df"
 	"github.com/pawelWritesCode/gdutils"
+	"github.com/pawelWritesCode/gdutils/pkg/httpcache"
+	"github.com/pawelWritesCode/gdutils/pkg/pathfinder"
 	"github.com/pawelWritesCode/gdutils/pkg/timeutils"
 	"github.com/pawelWritesCode/gdutils/pkg/types"
+	"github.com/xeipuuv/gojsonpointer"
+	"github.com/xeipuuv/gojsonschema"
+	"golang.org/x/net/html"
+)
+
+const (
+	// CORSPreflightRequestCacheKey represents cache key under which last CORS preflight request is saved.
+	CORSPreflightRequestCacheKey = "CORS_PREFLIGHT_REQUEST"
+
+	// LastHTTPRequestAttempts represents cache key under which number of attempts of last request sent with retries is saved.
+	LastHTTPRequestAttempts = "LAST_HTTP_REQUEST_ATTEMPTS"
+
+	// LastThroughputRequestsCount represents cache key under which number of requests sent during last throughput test is saved.
+	LastThroughputRequestsCount = "LAST_THROUGHPUT_REQUESTS_COUNT"
+
+	// LastSchemaValidationError represents cache key under which error of last expected to fail JSON schema validation is saved.
+	LastSchemaValidationError = "LAST_SCHEMA_VALIDATION_ERROR"
+
+	// SequencesCacheKey represents cache key under which current values of named sequences are saved.
+	SequencesCacheKey = "SEQUENCES"
 )
 
 // Scenario is entity that contains utility services and holds methods used behind godog steps.
 type Scenario struct {
 	// APIContext holds utility services and methods for working with HTTP(s) API.
 	APIContext *gdutils.APIContext
+
+	// JSONSchemaDir is full OS path to directory with JSON schemas, relative schema references are resolved against it.
+	JSONSchemaDir string
+
+	// MaskingRegExp describes masked values, for example of credit card numbers. If nil, DefaultMaskingRegExp is used.
+	MaskingRegExp *regexp.Regexp
 }
 
+// CharsetNames holds names of charsets available in steps generating random data or asserting on characters.
+var CharsetNames = []string{"ASCII", "UNICODE", "polish", "english", "russian", "japanese", "emoji"}
+
+// DefaultMaskingRegExp matches values consisting of masking characters, optionally followed by up to 4
+// visible characters, for example: ******** or ****1234
+var DefaultMaskingRegExp = regexp.MustCompile(`^[*•●]+[^*•●]{0,4}$`)
+
 // IGenerateARandomRunesOfLengthWithCharactersAndSaveItAs creates random runes generator func using provided charset.
 // Returned func creates runes from provided range and preserve it under given cacheKey in scenario cache.
 func (s *Scenario) IGenerateARandomRunesOfLengthWithCharactersAndSaveItAs(from, to int, charset string, cacheKey string) error {
-	var generateWordFunc func(from, to int, cacheKey string) error
-
-	switch strings.ToLower(charset) {
-	case "ascii":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.ASCII)
-	case "unicode":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.Unicode)
-	case "polish":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.Polish)
-	case "english":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.English)
-	case "russian":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.Russian)
-	case "japanese":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.Japanese)
-	case "emoji":
-		generateWordFunc = s.APIContext.GeneratorRandomRunes(ch.Emoji)
-	default:
-		return fmt.Errorf("unknown charset '%s', available: ascii, unicode, polish, english, russian, japanese, emoji", charset)
+	charsetRunes, err := charsetByName(charset)
+	if err != nil {
+		return err
 	}
 
-	return generateWordFunc(from, to, cacheKey)
+	return s.APIContext.GeneratorRandomRunes(charsetRunes)(from, to, cacheKey)
 }
 
 // IGenerateARandomNumberInTheRangeFromToAndSaveItAs generates random number from provided range
@@ -65,27 +109,12 @@ func (s *Scenario) IGenerateARandomNumberInTheRangeFromToAndSaveItAs(numberType
 // Each sentence has length from - to as provided in params and is saved in scenario cache under provided cacheKey.
 func (s *Scenario) IGenerateARandomSentenceInTheRangeFromToWordsAndSaveItAs(minWordLength, maxWordLength int) func(from, to int, charset string, cacheKey string) error {
 	return func(from, to int, charset string, cacheKey string) error {
-		var generateSentenceFunc func(from, to int, cacheKey string) error
-		switch strings.ToLower(charset) {
-		case "ascii":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.ASCII, minWordLength, maxWordLength)
-		case "unicode":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.Unicode, minWordLength, maxWordLength)
-		case "polish":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.Polish, minWordLength, maxWordLength)
-		case "english":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.English, minWordLength, maxWordLength)
-		case "russian":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.Russian, minWordLength, maxWordLength)
-		case "japanese":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.Japanese, minWordLength, maxWordLength)
-		case "emoji":
-			generateSentenceFunc = s.APIContext.GeneratorRandomSentence(ch.Emoji, minWordLength, maxWordLength)
-		default:
-			return fmt.Errorf("unknown charset '%s', available: ascii, unicode, polish, english, russian, japanese, emoji", charset)
-		}
-
-		return generateSentenceFunc(from, to, cacheKey)
+		charsetRunes, err := charsetByName(charset)
+		if err != nil {
+			return err
+		}
+
+		return s.APIContext.GeneratorRandomSentence(charsetRunes, minWordLength, maxWordLength)(from, to, cacheKey)
 	}
 }
 
@@ -96,6 +125,93 @@ func (s *Scenario) IGenerateRandomBoolValueAndSaveItAs(cacheKey string) error {
 	return nil
 }
 
+// IGenerateNextSequenceValueForAndSaveItAs increments integer sequence of given name and saves its value in cache
+// under given key. Each sequence starts from 1 and is independent of other sequences. Sequences are reset together
+// with cache, so every scenario starts them anew.
+func (s *Scenario) IGenerateNextSequenceValueForAndSaveItAs(sequenceName, cacheKey string) error {
+	sequences := map[string]int{}
+	if saved, err := s.APIContext.Cache.GetSaved(SequencesCacheKey); err == nil {
+		var ok bool
+		if sequences, ok = saved.(map[string]int); !ok {
+			return fmt.Errorf("value saved under key '%s' should be map[string]int, got: %T", SequencesCacheKey, saved)
+		}
+	}
+
+	sequences[sequenceName]++
+	s.APIContext.Cache.Save(SequencesCacheKey, sequences)
+	s.APIContext.Cache.Save(cacheKey, sequences[sequenceName])
+
+	return nil
+}
+
+// IGenerateStringMatchingRegExpAndSaveItAs generates random string matching provided regExp
+// and save it in cache under given key.
+func (s *Scenario) IGenerateStringMatchingRegExpAndSaveItAs(patternTemplate, cacheKey string) error {
+	pattern, err := s.APIContext.TemplateEngine.Replace(patternTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'regExp' template, err: %w", err)
+	}
+
+	generated, err := generateStringMatchingRegExp(pattern)
+	if err != nil {
+		return err
+	}
+
+	s.APIContext.Cache.Save(cacheKey, generated)
+
+	return nil
+}
+
+// IGenerateWeightedRandomChoiceAndSaveItAs picks one of provided values with probability proportional to its weight
+// and save it in cache under given key. Argument "choicesDoc" should be YAML or JSON map of value -> weight.
+func (s *Scenario) IGenerateWeightedRandomChoiceAndSaveItAs(cacheKey string, choicesDoc *godog.DocString) error {
+	choicesTemplate, err := s.APIContext.TemplateEngine.Replace(choicesDoc.Content, s.APIContext.Cache.All())
+	if err != nil {
+		return err
+	}
+
+	formatter := s.APIContext.Formatters.YAML
+	if json.Valid([]byte(choicesTemplate)) {
+		formatter = s.APIContext.Formatters.JSON
+	}
+
+	var choices map[string]float64
+	if err = formatter.Deserialize([]byte(choicesTemplate), &choices); err != nil {
+		return fmt.Errorf("could not deserialize choices, expected map of value -> weight, err: %w", err)
+	}
+
+	if len(choices) == 0 {
+		return errors.New("no choices provided")
+	}
+
+	values := make([]string, 0, len(choices))
+	total := 0.0
+	for value, weight := range choices {
+		if weight <= 0 {
+			return fmt.Errorf("weight of value '%s' should be positive, got %v", value, weight)
+		}
+
+		values = append(values, value)
+		total += weight
+	}
+
+	sort.Strings(values)
+
+	pick := rand.Float64() * total
+	for _, value := range values {
+		pick -= choices[value]
+		if pick < 0 {
+			s.APIContext.Cache.Save(cacheKey, value)
+
+			return nil
+		}
+	}
+
+	s.APIContext.Cache.Save(cacheKey, values[len(values)-1])
+
+	return nil
+}
+
 // IGenerateCurrentTimeAndTravelByAndSaveItAs creates current time object, move timeDuration in time and
 // save it in cache under given cacheKey.
 func (s *Scenario) IGenerateCurrentTimeAndTravelByAndSaveItAs(timeDirection, timeDuration, cacheKey string) error {
@@ -124,12 +240,99 @@ func (s *Scenario) IPrepareNewRequestToAndSaveItAs(method, urlTemplate, cacheKey
 	return s.APIContext.RequestPrepare(method, urlTemplate, cacheKey)
 }
 
+// IPrepareNewRequestToUnixSocket prepares new request to resource available under path of server
+// listening on Unix domain socket and saves it in cache under cacheKey.
+// socketPath may be full OS path or unix:// URL, for example: unix:///var/run/app.sock
+func (s *Scenario) IPrepareNewRequestToUnixSocket(method, socketPath, path, cacheKey string) error {
+	socketPath, err := s.APIContext.TemplateEngine.Replace(socketPath, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'socket path' template, err: %w", err)
+	}
+
+	path, err = s.APIContext.TemplateEngine.Replace(path, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'path' template, err: %w", err)
+	}
+
+	reqURL, err := unixSocketURL(socketPath, path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("can't create request due to err: %w", err)
+	}
+
+	// URL host is used only to dial socket, server receives neutral Host header.
+	req.Host = "localhost"
+	s.APIContext.Cache.Save(cacheKey, req)
+
+	return nil
+}
+
 // ISetFollowingHeadersForPreparedRequest sets provided headers for previously prepared request.
 // incoming data should be in format acceptable by injected s.APIContext.Deserializer
 func (s *Scenario) ISetFollowingHeadersForPreparedRequest(cacheKey string, headersTemplate *godog.DocString) error {
 	return s.APIContext.RequestSetHeaders(cacheKey, headersTemplate.Content)
 }
 
+// ISetIfNoneMatchForPreparedRequest sets If-None-Match header for previously prepared request.
+// etagTemplate may contain template values, for example ETag saved from previous response.
+func (s *Scenario) ISetIfNoneMatchForPreparedRequest(etagTemplate, cacheKey string) error {
+	return s.setPreparedRequestHeader(cacheKey, "If-None-Match", etagTemplate)
+}
+
+// ISetIfModifiedSinceForPreparedRequest sets If-Modified-Since header for previously prepared request.
+// dateTemplate may contain template values and should be valid HTTP date, for example: Mon, 02 Jan 2006 15:04:05 GMT
+func (s *Scenario) ISetIfModifiedSinceForPreparedRequest(dateTemplate, cacheKey string) error {
+	date, err := s.APIContext.TemplateEngine.Replace(dateTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'date' template, err: %w", err)
+	}
+
+	if _, err = http.ParseTime(date); err != nil {
+		return fmt.Errorf("'%s' is not valid HTTP date, err: %w", date, err)
+	}
+
+	return s.setPreparedRequestHeader(cacheKey, "If-Modified-Since", date)
+}
+
+// ISetHeadersFromFileForPreparedRequest sets headers read from file for previously prepared request.
+// File should contain YAML or JSON map of header name -> value and may include template values.
+// Relative file path is resolved against current working directory, the same way as JSON schemas directory.
+func (s *Scenario) ISetHeadersFromFileForPreparedRequest(filePathTemplate, cacheKey string) error {
+	filePath, err := s.APIContext.TemplateEngine.Replace(filePathTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'file path' template, err: %w", err)
+	}
+
+	headers, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("could not read headers file, err: %w", err)
+	}
+
+	return s.APIContext.RequestSetHeaders(cacheKey, string(headers))
+}
+
+// ISetGeneratedIdempotencyKeyForPreparedRequest generates UUID and sets it as header of given name
+// for previously prepared request. Generated value is also saved in cache under key derived from request's cache key
+// and header name, for example: request "CREATE_USER" and header "Idempotency-Key" give "CREATE_USER_IDEMPOTENCY_KEY".
+func (s *Scenario) ISetGeneratedIdempotencyKeyForPreparedRequest(headerName, cacheKey string) error {
+	key, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("could not generate idempotency key, err: %w", err)
+	}
+
+	if err = s.setPreparedRequestHeader(cacheKey, headerName, key.String()); err != nil {
+		return err
+	}
+
+	s.APIContext.Cache.Save(cacheKey+"_"+strings.ToUpper(strings.ReplaceAll(headerName, "-", "_")), key.String())
+
+	return nil
+}
+
 // ISetFollowingCookiesForPreparedRequest sets cookies for previously prepared request
 // cookies template should be YAML or JSON deserializable on []http.Cookie
 func (s *Scenario) ISetFollowingCookiesForPreparedRequest(cacheKey string, cookies *godog.DocString) error {
@@ -151,235 +354,3893 @@ func (s *Scenario) ISetFollowingBodyForPreparedRequest(cacheKey string, bodyTemp
 	return s.APIContext.RequestSetBody(cacheKey, bodyTemplate.Content)
 }
 
-// ISendRequest sends previously prepared HTTP(s) request.
-func (s *Scenario) ISendRequest(cacheKey string) error {
-	return s.APIContext.RequestSend(cacheKey)
+// ISetBodyWithContentTypeForPreparedRequest sets body and Content-Type header for previously prepared request.
+// bodyTemplate may be in any format and both arguments accept template values.
+func (s *Scenario) ISetBodyWithContentTypeForPreparedRequest(contentType, cacheKey string, bodyTemplate *godog.DocString) error {
+	if err := s.APIContext.RequestSetBody(cacheKey, bodyTemplate.Content); err != nil {
+		return err
+	}
+
+	return s.setPreparedRequestHeader(cacheKey, "Content-Type", contentType)
 }
 
-// TheResponseShouldOrShouldNotHaveHeader checks whether last HTTP response has/hasn't given header.
-func (s *Scenario) TheResponseShouldOrShouldNotHaveHeader(not, name string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertResponseHeaderNotExists(name)
+// ISetBodyFromCachedBytesForPreparedRequest sets body of previously prepared request to bytes saved in cache
+// under srcKey. Cached value should be []byte or base64 encoded string. Body is not processed by template engine.
+func (s *Scenario) ISetBodyFromCachedBytesForPreparedRequest(srcKey, cacheKey string) error {
+	src, err := s.APIContext.Cache.GetSaved(srcKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain cached bytes, err: %w", err)
 	}
 
-	return s.APIContext.AssertResponseHeaderExists(name)
-}
+	var body []byte
+	switch v := src.(type) {
+	case []byte:
+		body = v
+	case string:
+		if body, err = base64.StdEncoding.DecodeString(v); err != nil {
+			return fmt.Errorf("cached value '%s' is not valid base64 encoded string, err: %w", srcKey, err)
+		}
+	default:
+		return fmt.Errorf("cached value '%s' should be []byte or base64 encoded string, got: %T", srcKey, src)
+	}
 
-// TheResponseShouldHaveHeaderOfValue checks whether last HTTP response has given header with provided value.
-func (s *Scenario) TheResponseShouldHaveHeaderOfValue(name, value string) error {
-	return s.APIContext.AssertResponseHeaderValueIs(name, value)
+	return s.setPreparedRequestRawBody(cacheKey, body)
 }
 
-// TheResponseStatusCodeShouldOrShouldNotBe checks last response status code.
-func (s *Scenario) TheResponseStatusCodeShouldOrShouldNotBe(not string, code int) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertStatusCodeIsNot(code)
+// ICompressPreparedRequestBodyWithGzip replaces body of previously prepared request with its gzip compressed form
+// and sets Content-Encoding: gzip header. It should be used after body was set, as last step before sending request.
+func (s *Scenario) ICompressPreparedRequestBodyWithGzip(cacheKey string) error {
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
 	}
 
-	return s.APIContext.AssertStatusCodeIs(code)
-}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err = writer.Write(body); err != nil {
+		return fmt.Errorf("could not gzip compress prepared request '%s' body, err: %w", cacheKey, err)
+	}
 
-// TheResponseShouldOrShouldNotHaveNode checks whether last response body contains or doesn't contain given node.
-// expr should be valid according to injected PathFinder for given data format
-func (s *Scenario) TheResponseShouldOrShouldNotHaveNode(dataFormat, not, exprTemplate string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertNodeNotExists(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("could not gzip compress prepared request '%s' body, err: %w", cacheKey, err)
 	}
 
-	return s.APIContext.AssertNodeExists(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
-}
+	if err = s.setPreparedRequestRawBody(cacheKey, compressed.Bytes()); err != nil {
+		return err
+	}
 
-// TheNodeShouldBeOfValue compares node value from expression to expected by user dataValue of given by user dataType
-// Available data types are listed in switch section in each case directive.
-// expr should be valid according to injected PathFinder for provided dataFormat.
-func (s *Scenario) TheNodeShouldBeOfValue(dataFormat, exprTemplate, dataType, dataValue string) error {
-	return s.APIContext.AssertNodeIsTypeAndValue(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(dataType), dataValue)
+	return s.setPreparedRequestHeader(cacheKey, "Content-Encoding", "gzip")
 }
 
-// TheNodeShouldBeOfValues compares node value from expression to expected by user one of values of given by user dataType
-// Available data types are listed in switch section in each case directive.
-// expr should be valid according to injected PathFinder for provided dataFormat.
-func (s *Scenario) TheNodeShouldBeOfValues(dataFormat, exprTemplate, dataType, valuesTemplates string) error {
-	return s.APIContext.AssertNodeIsTypeAndHasOneOfValues(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(dataType), valuesTemplates)
-}
+// ISetBodyFromTableForPreparedRequest sets body of previously prepared request to JSON built from table.
+// Table with two columns always describes single object as key-value pairs. Only first row "key | value"
+// is skipped as header, any other first row, for example "name | age", is key-value pair as well.
+// Table with more columns has header row with keys, each next row describes object. If there are many of them,
+// body is array of objects. Values are processed by template engine, then valid JSON values like 1, true, null
+// or "1" are used as they are, other values become strings.
+func (s *Scenario) ISetBodyFromTableForPreparedRequest(cacheKey string, table *godog.Table) error {
+	rows := make([][]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			cells = append(cells, cell.Value)
+		}
 
-// TheNodeShouldOrShouldNotContainSubString checks whether value of last HTTP response node, obtained using exprTemplate
-// is string type and contains/doesn't contain given substring
-func (s *Scenario) TheNodeShouldOrShouldNotContainSubString(dataFormat, exprTemplate, not, subTemplate string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertNodeNotContainsSubString(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, subTemplate)
+		rows = append(rows, cells)
 	}
 
-	return s.APIContext.AssertNodeContainsSubString(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, subTemplate)
-}
-
-// TheNodeShouldOrShouldNotBeSliceOfLength checks whether given key is slice and has/hasn't given length
-// expr should be valid according to injected PathFinder for provided dataFormat
-func (s *Scenario) TheNodeShouldOrShouldNotBeSliceOfLength(dataFormat, exprTemplate, not string, length int) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertNodeSliceLengthIsNot(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, length)
+	if len(rows) == 0 {
+		return errors.New("table should have at least one row")
 	}
 
-	return s.APIContext.AssertNodeSliceLengthIs(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, length)
-}
+	var data any
+	if len(rows[0]) == 2 {
+		// two columns can't be told apart from header with two keys, so they are read as key-value pairs
+		if strings.EqualFold(rows[0][0], "key") && strings.EqualFold(rows[0][1], "value") {
+			rows = rows[1:]
+		}
 
-// TheNodeShouldOrShouldNotBe checks whether node from last response body is/is not of provided type
-// goType may be one of: nil, string, int, float, bool, map, slice
-// expr should be valid according to injected PathResolver.
-func (s *Scenario) TheNodeShouldOrShouldNotBe(dataFormat, exprTemplate, not, goType string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertNodeIsNotType(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(goType))
+		object := map[string]any{}
+		for _, row := range rows {
+			value, err := s.tableValue(row[1])
+			if err != nil {
+				return err
+			}
+
+			object[row[0]] = value
+		}
+
+		data = object
+	} else {
+		if len(rows) < 2 {
+			return errors.New("table with header should have at least one row with values")
+		}
+
+		objects := make([]any, 0, len(rows)-1)
+		for _, row := range rows[1:] {
+			object := map[string]any{}
+			for i, key := range rows[0] {
+				value, err := s.tableValue(row[i])
+				if err != nil {
+					return err
+				}
+
+				object[key] = value
+			}
+
+			objects = append(objects, object)
+		}
+
+		data = objects
+		if len(objects) == 1 {
+			data = objects[0]
+		}
 	}
 
-	return s.APIContext.AssertNodeIsType(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(goType))
-}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("could not serialize table to JSON, err: %w", err)
+	}
 
-// TheResponseShouldHaveNodes checks whether last request body has keys defined in string separated by comma
-// nodeExpr should be valid according to injected PathFinder expressions separated by comma (,)
-func (s *Scenario) TheResponseShouldHaveNodes(dataFormat, nodesExpr string) error {
-	return s.APIContext.AssertNodesExist(df.DataFormat(strings.ToLower(dataFormat)), nodesExpr)
+	return s.setPreparedRequestRawBody(cacheKey, body)
 }
 
-// TheNodeShouldOrShouldNotMatchRegExp checks whether last response body node matches or doesn't match provided regExp.
-func (s *Scenario) TheNodeShouldOrShouldNotMatchRegExp(dataFormat, exprTemplate, not, regExpTemplate string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertNodeNotMatchesRegExp(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, regExpTemplate)
+// ThePreparedRequestBodyShouldBeValidJSON checks whether body of previously prepared request is valid JSON.
+func (s *Scenario) ThePreparedRequestBodyShouldBeValidJSON(cacheKey string) error {
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
 	}
 
-	return s.APIContext.AssertNodeMatchesRegExp(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, regExpTemplate)
-}
+	var tmp any
+	if err = json.Unmarshal(body, &tmp); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("prepared request '%s' body is not valid JSON, err: %w, near: %s", cacheKey, err, snippet(body, int(syntaxErr.Offset)))
+		}
 
-// TheResponseBodyShouldOrShouldNotHaveFormat checks whether last response body has given data format.
-// Available data formats are listed in format package.
-func (s *Scenario) TheResponseBodyShouldOrShouldNotHaveFormat(not, dataFormat string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertResponseFormatIsNot(df.DataFormat(strings.ToLower(dataFormat)))
+		return fmt.Errorf("prepared request '%s' body is not valid JSON, err: %w", cacheKey, err)
 	}
 
-	return s.APIContext.AssertResponseFormatIs(df.DataFormat(strings.ToLower(dataFormat)))
+	return nil
 }
 
-/*
-IValidateLastResponseBodyWithSchema validates last response body against JSON schema under provided reference.
-reference may be:
-  - full OS path to JSON schema
-  - relative path from JSON schema's dir which was passed in main_test to initialize *Scenario struct instance,
-  - URL
-*/
-func (s *Scenario) IValidateLastResponseBodyWithSchema(referenceTemplate string) error {
-	return s.APIContext.AssertResponseMatchesSchemaByReference(referenceTemplate)
+// ISendRequest sends previously prepared HTTP(s) request.
+func (s *Scenario) ISendRequest(cacheKey string) error {
+	return s.APIContext.RequestSend(cacheKey)
 }
 
-// IValidateLastResponseBodyWithFollowingSchema validates last response body against JSON schema provided by user.
-func (s *Scenario) IValidateLastResponseBodyWithFollowingSchema(schemaBytes *godog.DocString) error {
-	return s.APIContext.AssertResponseMatchesSchemaByString(schemaBytes.Content)
+// ISendCORSPreflightToWithOrigin sends CORS preflight OPTIONS request to given URL on behalf of provided origin,
+// asking whether request using given method is allowed. Response is saved as last response.
+func (s *Scenario) ISendCORSPreflightToWithOrigin(urlTemplate, originTemplate, method string) error {
+	if err := s.APIContext.RequestPrepare(http.MethodOptions, urlTemplate, CORSPreflightRequestCacheKey); err != nil {
+		return err
+	}
+
+	if err := s.setPreparedRequestHeader(CORSPreflightRequestCacheKey, "Origin", originTemplate); err != nil {
+		return err
+	}
+
+	if err := s.setPreparedRequestHeader(CORSPreflightRequestCacheKey, "Access-Control-Request-Method", strings.ToUpper(method)); err != nil {
+		return err
+	}
+
+	return s.APIContext.RequestSend(CORSPreflightRequestCacheKey)
 }
 
 /*
-TimeBetweenLastHTTPRequestResponseShouldBeLessThanOrEqualTo asserts that last HTTP request-response time
-is <= than expected timeInterval.
-timeInterval should be string acceptable by time.ParseDuration func
+SendPreparedRequestForDurationAndAssertMinRPS sends previously prepared HTTP(s) request repeatedly for given duration
+and asserts that achieved throughput of successful responses is at least minRPS requests per second.
+Response is considered successful when its status code is lower than 400. Last received response is saved as last response
+and number of all sent requests is saved in cache under LastThroughputRequestsCount key.
+duration should be string acceptable by time.ParseDuration func
 */
-func (s *Scenario) TimeBetweenLastHTTPRequestResponseShouldBeLessThanOrEqualTo(timeInterval string) error {
-	duration, err := time.ParseDuration(timeInterval)
+func (s *Scenario) SendPreparedRequestForDurationAndAssertMinRPS(cacheKey, duration string, minRPS float64) error {
+	timeInterval, err := time.ParseDuration(duration)
 	if err != nil {
 		return err
 	}
 
-	return s.APIContext.AssertTimeBetweenRequestAndResponseIs(duration)
-}
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
+	}
 
-// TheResponseShouldOrShouldNotHaveCookie checks whether last HTTP(s) response has cookie of given name.
-func (s *Scenario) TheResponseShouldOrShouldNotHaveCookie(not, name string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertResponseCookieNotExists(name)
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
 	}
 
-	return s.APIContext.AssertResponseCookieExists(name)
-}
+	var sent, successful int
+	start := time.Now()
+	for time.Since(start) < timeInterval {
+		r := req.Clone(req.Context())
+		r.Body = io.NopCloser(bytes.NewReader(body))
 
-// TheResponseShouldHaveCookieOfValue checks whether last HTTP(s) response has cookie of given name and value.
-func (s *Scenario) TheResponseShouldHaveCookieOfValue(name, valueTemplate string) error {
-	return s.APIContext.AssertResponseCookieValueIs(name, valueTemplate)
+		resp, _, err := s.sendAndSaveLastResponse(r)
+		if err != nil {
+			return err
+		}
+
+		sent++
+		s.APIContext.Cache.Save(LastThroughputRequestsCount, sent)
+		if resp.StatusCode < 400 {
+			successful++
+		}
+	}
+
+	rps := float64(successful) / time.Since(start).Seconds()
+	if rps < minRPS {
+		return fmt.Errorf("throughput should be at least %.2f rps, but achieved %.2f rps (%d successful responses)", minRPS, rps, successful)
+	}
+
+	return nil
 }
 
-// TheResponseCookieShouldOrShouldNotMatchRegExp checks whether last HTTP(s) response has cookie of given name and value
-// matches/doesn't match provided regExp.
-func (s *Scenario) TheResponseCookieShouldOrShouldNotMatchRegExp(name, not, regExpTemplate string) error {
-	if len(not) > 0 {
-		return s.APIContext.AssertResponseCookieValueNotMatchesRegExp(name, regExpTemplate)
+// ISendPreparedRequestTwiceAndBodiesShouldBeByteIdentical sends previously prepared HTTP(s) request twice
+// and checks whether both response bodies are identical byte by byte. Second response is saved as last response.
+func (s *Scenario) ISendPreparedRequestTwiceAndBodiesShouldBeByteIdentical(cacheKey string) error {
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
 	}
 
-	return s.APIContext.AssertResponseCookieValueMatchesRegExp(name, regExpTemplate)
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	var respBodies [2][]byte
+	for i := range respBodies {
+		r := req.Clone(req.Context())
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if _, respBodies[i], err = s.sendAndSaveLastResponse(r); err != nil {
+			return err
+		}
+	}
+
+	first, second := respBodies[0], respBodies[1]
+	if bytes.Equal(first, second) {
+		return nil
+	}
+
+	offset := 0
+	for offset < len(first) && offset < len(second) && first[offset] == second[offset] {
+		offset++
+	}
+
+	return fmt.Errorf("response bodies differ at byte offset %d (lengths: %d and %d), first: %s, second: %s",
+		offset, len(first), len(second), snippet(first, offset), snippet(second, offset))
 }
 
-// IValidateNodeWithSchemaReference validates last response body node against schema as provided in reference
-func (s *Scenario) IValidateNodeWithSchemaReference(dataFormat, exprTemplate, referenceTemplate string) error {
-	return s.APIContext.AssertNodeMatchesSchemaByReference(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, referenceTemplate)
+/*
+RequestShouldRequireHeader sends previously prepared HTTP(s) request twice: first without header, then with header
+set to given value, and checks whether responses have expected status codes. It is useful for testing endpoints
+protected by header, for example Authorization. Response to request with header is saved as last response.
+valueTemplate may contain template values.
+*/
+func (s *Scenario) RequestShouldRequireHeader(cacheKey, headerName, valueTemplate string, withoutStatus, withStatus int) error {
+	value, err := s.APIContext.TemplateEngine.Replace(valueTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'value' template, err: %w", err)
+	}
+
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	var statuses [2]int
+	for i, withHeader := range []bool{false, true} {
+		// request is cloned, so header changes do not stick to prepared request
+		r := req.Clone(req.Context())
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if withHeader {
+			r.Header.Set(headerName, value)
+		} else {
+			r.Header.Del(headerName)
+		}
+
+		resp, _, err := s.sendAndSaveLastResponse(r)
+		if err != nil {
+			return err
+		}
+
+		statuses[i] = resp.StatusCode
+	}
+
+	if statuses[0] != withoutStatus || statuses[1] != withStatus {
+		return fmt.Errorf("request '%s' should return status code %d without header '%s' and %d with it, got: %d without and %d with",
+			cacheKey, withoutStatus, headerName, withStatus, statuses[0], statuses[1])
+	}
+
+	return nil
 }
 
-// IValidateNodeWithSchemaString validates last response body JSON node against schema
-func (s *Scenario) IValidateNodeWithSchemaString(dataFormat, exprTemplate string, schemaTemplate *godog.DocString) error {
-	return s.APIContext.AssertNodeMatchesSchemaByString(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, schemaTemplate.Content)
+/*
+ISendPreparedRequestWithIdempotencyKeyTwiceAndAssertSameResult generates idempotency key, sets it as header
+of given name for previously prepared request, sends request twice and checks whether both responses have
+the same status code and body. JSON bodies are compared semantically, other bodies byte by byte.
+Generated key is saved in cache, the same way as by ISetGeneratedIdempotencyKeyForPreparedRequest,
+and second response is saved as last response.
+*/
+func (s *Scenario) ISendPreparedRequestWithIdempotencyKeyTwiceAndAssertSameResult(cacheKey, keyHeaderName string) error {
+	if err := s.ISetGeneratedIdempotencyKeyForPreparedRequest(keyHeaderName, cacheKey); err != nil {
+		return err
+	}
+
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	var statuses [2]int
+	var respBodies [2][]byte
+	for i := range respBodies {
+		r := req.Clone(req.Context())
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var resp *http.Response
+		resp, respBodies[i], err = s.sendAndSaveLastResponse(r)
+		if err != nil {
+			return err
+		}
+		statuses[i] = resp.StatusCode
+	}
+
+	var diffs []string
+	if statuses[0] != statuses[1] {
+		diffs = append(diffs, fmt.Sprintf("status code: %d, then %d", statuses[0], statuses[1]))
+	}
+
+	var first, second any
+	if json.Unmarshal(respBodies[0], &first) == nil && json.Unmarshal(respBodies[1], &second) == nil {
+		diffs = append(diffs, jsonDiff("$", first, second)...)
+	} else if !bytes.Equal(respBodies[0], respBodies[1]) {
+		diffs = append(diffs, fmt.Sprintf("body: %s, then %s", snippet(respBodies[0], 0), snippet(respBodies[1], 0)))
+	}
+
+	if len(diffs) > 0 {
+		return fmt.Errorf("responses to request '%s' sent twice with the same '%s' header should match, differences (expected is first response, got is second one):\n%s",
+			cacheKey, keyHeaderName, strings.Join(diffs, "\n"))
+	}
+
+	return nil
 }
 
-// ISaveAs saves into cache arbitrary passed value
-func (s *Scenario) ISaveAs(valueTemplate, cacheKey string) error {
-	return s.APIContext.Save(valueTemplate, cacheKey)
+/*
+ISendPreparedRequestWithRetriesOn5xx sends previously prepared HTTP(s) request and retries it at most maxRetries times,
+as long as response status code is 5xx, waiting backoff between attempts. Final response is saved as last response
+and number of attempts is saved in cache under LastHTTPRequestAttempts key. If response is still 5xx after all retries,
+error containing number of attempts is returned.
+backoff should be string acceptable by time.ParseDuration func
+*/
+func (s *Scenario) ISendPreparedRequestWithRetriesOn5xx(cacheKey string, maxRetries int, backoff string) error {
+	backoffInterval, err := time.ParseDuration(backoff)
+	if err != nil {
+		return err
+	}
+
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	var resp *http.Response
+	attempts := 0
+	for attempts <= maxRetries {
+		if attempts > 0 {
+			time.Sleep(backoffInterval)
+		}
+
+		r := req.Clone(req.Context())
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		resp, _, err = s.sendAndSaveLastResponse(r)
+		attempts++
+		s.APIContext.Cache.Save(LastHTTPRequestAttempts, attempts)
+		if err != nil {
+			return fmt.Errorf("attempt %d failed, err: %w", attempts, err)
+		}
+
+		if resp.StatusCode < 500 {
+			break
+		}
+	}
+
+	if s.APIContext.Debugger.IsOn() {
+		s.APIContext.Debugger.Print(fmt.Sprintf("request %s %s sent %d time(s), final status code: %d", req.Method, req.URL.String(), attempts, resp.StatusCode))
+	}
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("request %s %s still responded with status code %d after %d attempts", req.Method, req.URL.String(), resp.StatusCode, attempts)
+	}
+
+	return nil
 }
 
-// ISaveFollowingAs saves into cache arbitrary passed data. Data may be multiline.
-func (s *Scenario) ISaveFollowingAs(cacheKey string, data *godog.DocString) error {
-	return s.ISaveAs(data.Content, cacheKey)
+/*
+ISendPreparedRequestStreamingAndAssertMaxBodySize sends previously prepared HTTP(s) request and reads its response body
+up to maxBytes bytes. If body is bigger, reading is aborted with error instead of buffering whole body in memory.
+Response with body within limit is saved as last response.
+*/
+func (s *Scenario) ISendPreparedRequestStreamingAndAssertMaxBodySize(cacheKey string, maxBytes int) error {
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	_, _, err = s.sendAndSaveLastResponseReadingWith(req, func(resp *http.Response) ([]byte, error) {
+		if resp.ContentLength > int64(maxBytes) {
+			return nil, fmt.Errorf("response body should not exceed %d bytes, but Content-Length is %d", maxBytes, resp.ContentLength)
+		}
+
+		// one byte over the limit is enough to tell, that body exceeds it
+		body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxBytes)+1))
+		if err != nil {
+			return nil, fmt.Errorf("could not read response body, err: %w", err)
+		}
+
+		if len(body) > maxBytes {
+			return nil, fmt.Errorf("response body should not exceed %d bytes, reading was aborted", maxBytes)
+		}
+
+		return body, nil
+	})
+
+	return err
 }
 
-// ISaveFromTheLastResponseNodeAs saves from last response json node under given cache key.
-func (s *Scenario) ISaveFromTheLastResponseNodeAs(dataFormat, exprTemplate, cacheKey string) error {
-	return s.APIContext.SaveNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, cacheKey)
+// ISendPreparedRequestAcceptingEncodingAndAssert sends previously prepared HTTP(s) request with Accept-Encoding header
+// and checks whether response Content-Encoding is equal to expectedEncoding. Response body is not decompressed.
+// Expected encoding "identity" means that response should not be encoded at all.
+func (s *Scenario) ISendPreparedRequestAcceptingEncodingAndAssert(cacheKey, acceptEncoding, expectedEncoding string) error {
+	body, err := s.preparedRequestBody(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	// explicitly set Accept-Encoding header turns off transparent decompression of response body,
+	// request is cloned, so header does not stick to prepared request
+	r := req.Clone(req.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, _, err := s.sendAndSaveLastResponse(r)
+	if err != nil {
+		return err
+	}
+
+	encoding := resp.Header.Get("Content-Encoding")
+	if encoding == "" {
+		encoding = "identity"
+	}
+
+	if !strings.EqualFold(encoding, expectedEncoding) {
+		return fmt.Errorf("response to request accepting encoding '%s' should have encoding '%s', got: '%s'", acceptEncoding, expectedEncoding, encoding)
+	}
+
+	return nil
 }
 
-// ISaveFromTheLastResponseHeaderAs saves from last response header value under given cache key
-func (s *Scenario) ISaveFromTheLastResponseHeaderAs(headerName, cacheKey string) error {
-	return s.APIContext.SaveHeader(headerName, cacheKey)
+/*
+ISendPreparedRequestReadingBodySlowly sends previously prepared HTTP(s) request and reads its response body
+at most bytesPerSecond bytes per second, simulating slow client. Fully read response is saved as last response
+and time of receiving last HTTP(s) response is time of reading its body to the end.
+*/
+func (s *Scenario) ISendPreparedRequestReadingBodySlowly(cacheKey string, bytesPerSecond int) error {
+	if bytesPerSecond <= 0 {
+		return fmt.Errorf("bytes per second should be greater than 0, got: %d", bytesPerSecond)
+	}
+
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	chunkSize := bytesPerSecond / 10
+	if chunkSize == 0 {
+		chunkSize = 1
+	}
+
+	_, _, err = s.sendAndSaveLastResponseReadingWith(req, func(resp *http.Response) ([]byte, error) {
+		var body bytes.Buffer
+		chunk := make([]byte, chunkSize)
+		for {
+			n, err := resp.Body.Read(chunk)
+			body.Write(chunk[:n])
+			// last chunk may come together with io.EOF, so it is throttled as well
+			time.Sleep(time.Duration(n) * time.Second / time.Duration(bytesPerSecond))
+			if errors.Is(err, io.EOF) {
+				return body.Bytes(), nil
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("could not read response body, err: %w", err)
+			}
+		}
+	})
+
+	return err
 }
 
-// IPrintLastResponseBody prints response body from last scenario request
-func (s *Scenario) IPrintLastResponseBody() error {
-	return s.APIContext.DebugPrintResponseBody()
+// TheResponseShouldOrShouldNotHaveHeader checks whether last HTTP response has/hasn't given header.
+func (s *Scenario) TheResponseShouldOrShouldNotHaveHeader(not, name string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertResponseHeaderNotExists(name)
+	}
+
+	return s.APIContext.AssertResponseHeaderExists(name)
 }
 
-// IPrintCacheData prints all current scenario cache data.
-func (s *Scenario) IPrintCacheData() error {
-	fmt.Printf("%#v", s.APIContext.Cache.All())
+// TheResponseShouldHaveHeaderOfValue checks whether last HTTP response has given header with provided value.
+func (s *Scenario) TheResponseShouldHaveHeaderOfValue(name, value string) error {
+	return s.APIContext.AssertResponseHeaderValueIs(name, value)
+}
+
+// TheResponseETagShouldDifferFromCached checks whether last HTTP(s) response ETag header differs from
+// ETag saved in cache under cacheKey, for example using ISaveFromTheLastResponseHeaderAs method.
+func (s *Scenario) TheResponseETagShouldDifferFromCached(cacheKey string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	etag := lastResp.Header.Get("ETag")
+	if etag == "" {
+		return errors.New("last HTTP(s) response does not have header ETag")
+	}
+
+	cachedETag, err := s.cachedString(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	if etag == cachedETag {
+		return fmt.Errorf("last HTTP(s) response ETag %s should differ from cached '%s' ETag %s", etag, cacheKey, cachedETag)
+	}
+
+	return nil
+}
+
+// TheResponseHeaderIntShouldBeBetween checks whether last HTTP(s) response header is integer
+// from inclusive range <min, max>, for example X-RateLimit-Remaining.
+func (s *Scenario) TheResponseHeaderIntShouldBeBetween(name string, min, max int) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	values := lastResp.Header.Values(name)
+	if len(values) == 0 {
+		return fmt.Errorf("last HTTP(s) response does not have header '%s'", name)
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil {
+		return fmt.Errorf("last HTTP(s) response header '%s' should be integer, got: '%s'", name, values[0])
+	}
+
+	if value < min || value > max {
+		return fmt.Errorf("last HTTP(s) response header '%s' should be integer between %d and %d, got: %d", name, min, max, value)
+	}
 
 	return nil
 }
 
 /*
-IWait waits for provided time interval amount of time
-timeInterval should be string valid for time.ParseDuration func,
-for example: 3s, 1h, 30ms
+TheResponseDateHeaderShouldBeWithin checks whether Date header of last HTTP(s) response is HTTP-date
+no further than timeInterval from current time. Date in the future is accepted as well, to account for clock skew
+between client and server. Current time is truncated to seconds, because HTTP-date has seconds precision.
+timeInterval should be string acceptable by time.ParseDuration func
 */
-func (s *Scenario) IWait(timeInterval string) error {
-	duration, err := time.ParseDuration(timeInterval)
+func (s *Scenario) TheResponseDateHeaderShouldBeWithin(timeInterval string) error {
+	interval, err := time.ParseDuration(timeInterval)
 	if err != nil {
-		return err
+		return fmt.Errorf("could not parse time interval '%s', err: %w", timeInterval, err)
 	}
 
-	return s.APIContext.Wait(duration)
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	header := lastResp.Header.Get("Date")
+	if header == "" {
+		return errors.New("last HTTP(s) response does not have header 'Date'")
+	}
+
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return fmt.Errorf("last HTTP(s) response header 'Date' should be valid HTTP-date, got: '%s', err: %w", header, err)
+	}
+
+	delta := time.Now().Truncate(time.Second).Sub(date)
+	if delta < 0 {
+		delta = -delta
+	}
+
+	if delta > interval {
+		return fmt.Errorf("last HTTP(s) response header 'Date' should be within %s of now, got: %s, which differs by %s",
+			interval, date.UTC().Format(http.TimeFormat), delta)
+	}
+
+	return nil
 }
 
-// IStartDebugMode starts debugging mode
-func (s *Scenario) IStartDebugMode() error {
-	return s.APIContext.DebugStart()
+// TheResponseShouldBeRateLimited checks whether last HTTP(s) response has status code 429 Too Many Requests
+// and at least one rate limit header: Retry-After, X-RateLimit-* or RateLimit-*.
+func (s *Scenario) TheResponseShouldBeRateLimited() error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	var problems []string
+	if lastResp.StatusCode != http.StatusTooManyRequests {
+		problems = append(problems, fmt.Sprintf("status code should be %d, got: %d", http.StatusTooManyRequests, lastResp.StatusCode))
+	}
+
+	if len(rateLimitHeaders(lastResp.Header)) == 0 {
+		problems = append(problems, "none of rate limit headers is present, expected at least one of: Retry-After, X-RateLimit-*, RateLimit-*")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("last HTTP(s) response should be rate limited, but: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
 }
 
-// IStopDebugMode stops debugging mode
-func (s *Scenario) IStopDebugMode() error {
-	return s.APIContext.DebugStop()
+// ISaveRateLimitResetTimeAs saves time when rate limit of last HTTP(s) response resets under given cache key,
+// as time.Time. Time is obtained from Retry-After, X-RateLimit-Reset or RateLimit-Reset header, in that order.
+func (s *Scenario) ISaveRateLimitResetTimeAs(cacheKey string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	reset, err := rateLimitReset(lastResp.Header, time.Now())
+	if err != nil {
+		return fmt.Errorf("could not obtain rate limit reset time from last HTTP(s) response, err: %w", err)
+	}
+
+	s.APIContext.Cache.Save(cacheKey, reset)
+
+	return nil
 }
 
-// IStopScenarioExecution stops scenario execution
-func (s *Scenario) IStopScenarioExecution() error {
-	return errors.New("scenario stopped")
+// TheResponseVaryShouldInclude checks whether last HTTP(s) response Vary header lists given header name.
+// Header names are compared case-insensitively and Vary: * includes every header.
+func (s *Scenario) TheResponseVaryShouldInclude(headerName string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	var vary []string
+	for _, value := range lastResp.Header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				vary = append(vary, name)
+			}
+		}
+	}
+
+	for _, name := range vary {
+		if name == "*" || strings.EqualFold(name, headerName) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("last HTTP(s) response Vary header should include '%s', but it lists: %v", headerName, vary)
+}
+
+// TheResponseAllowHeaderShouldInclude checks whether last HTTP(s) response Allow header lists given method.
+// Allow header is usually returned in response to OPTIONS request or with status code 405 Method Not Allowed.
+// Methods are compared case-sensitively, as HTTP specification requires.
+func (s *Scenario) TheResponseAllowHeaderShouldInclude(method string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	allowed := []string{}
+	for _, value := range lastResp.Header.Values("Allow") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				allowed = append(allowed, name)
+			}
+		}
+	}
+
+	for _, name := range allowed {
+		if name == method {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("last HTTP(s) response Allow header should include '%s', but it lists: %v", method, allowed)
+}
+
+// TheResponseLinkHeaderShouldHaveRelation checks whether last HTTP(s) response Link header has link of given relation.
+func (s *Scenario) TheResponseLinkHeaderShouldHaveRelation(rel string) error {
+	_, err := s.lastResponseLinkRelationURL(rel)
+
+	return err
+}
+
+// TheResponseShouldAllowOrigin checks whether last HTTP(s) response Access-Control-Allow-Origin header
+// allows provided origin, either explicitly or by wildcard "*".
+func (s *Scenario) TheResponseShouldAllowOrigin(originTemplate string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	origin, err := s.APIContext.TemplateEngine.Replace(originTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'origin' template, err: %w", err)
+	}
+
+	allowed := lastResp.Header.Get("Access-Control-Allow-Origin")
+	if allowed == "" {
+		return fmt.Errorf("last HTTP(s) response should allow origin '%s', but it does not have header Access-Control-Allow-Origin", origin)
+	}
+
+	if allowed != "*" && allowed != origin {
+		return fmt.Errorf("last HTTP(s) response should allow origin '%s', but Access-Control-Allow-Origin is: '%s'", origin, allowed)
+	}
+
+	return nil
+}
+
+// TheResponseLocationShouldBe checks whether last HTTP(s) response Location header points to given URL.
+// Relative URLs are resolved against URL of last HTTP(s) request before comparison, so relative and absolute
+// forms of the same URL are equal. Redirects are followed by default HTTP(s) client, so to check redirect response,
+// use IDoNotFollowRedirects method first.
+func (s *Scenario) TheResponseLocationShouldBe(urlTemplate string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	expectedURL, err := s.APIContext.TemplateEngine.Replace(urlTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'url' template, err: %w", err)
+	}
+
+	location := lastResp.Header.Get("Location")
+	if location == "" {
+		return errors.New("last HTTP(s) response does not have header Location")
+	}
+
+	actual, err := resolveResponseURL(lastResp, location)
+	if err != nil {
+		return fmt.Errorf("could not parse Location '%s', err: %w", location, err)
+	}
+
+	expected, err := resolveResponseURL(lastResp, expectedURL)
+	if err != nil {
+		return fmt.Errorf("could not parse expected URL '%s', err: %w", expectedURL, err)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("last HTTP(s) response Location should be '%s' (%s), but is '%s' (%s)", expectedURL, expected, location, actual)
+	}
+
+	return nil
+}
+
+// TheLastRequestShouldHaveBeen checks whether last sent HTTP(s) request used given method and URL.
+func (s *Scenario) TheLastRequestShouldHaveBeen(method, urlTemplate string) error {
+	req, err := s.lastRequest()
+	if err != nil {
+		return err
+	}
+
+	expectedURL, err := s.APIContext.TemplateEngine.Replace(urlTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'url' template, err: %w", err)
+	}
+
+	if req.Method != method || req.URL.String() != expectedURL {
+		return fmt.Errorf("last request should have been %s %s, but was %s %s", method, expectedURL, req.Method, req.URL.String())
+	}
+
+	return nil
+}
+
+// TheResponseCompressionRatioShouldBeAtLeast checks whether gzip compressed last HTTP(s) response body
+// is at least ratio times smaller than decompressed one. Ratio is decompressed size divided by compressed size.
+// HTTP(s) client decompresses body transparently unless Accept-Encoding header is set explicitly,
+// so request should have header Accept-Encoding: gzip.
+func (s *Scenario) TheResponseCompressionRatioShouldBeAtLeast(ratio float64) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	if lastResp.Uncompressed {
+		return errors.New("last HTTP(s) response body was decompressed by HTTP(s) client, set request header Accept-Encoding: gzip explicitly")
+	}
+
+	if encoding := lastResp.Header.Get("Content-Encoding"); !strings.EqualFold(encoding, "gzip") {
+		return fmt.Errorf("last HTTP(s) response should have header Content-Encoding: gzip, got: '%s'", encoding)
+	}
+
+	compressed, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return err
+	}
+
+	if len(compressed) == 0 {
+		return errors.New("last HTTP(s) response body is empty")
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("could not decompress last HTTP(s) response body, err: %w", err)
+	}
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("could not decompress last HTTP(s) response body, err: %w", err)
+	}
+
+	actualRatio := float64(len(decompressed)) / float64(len(compressed))
+	if actualRatio < ratio {
+		return fmt.Errorf("compression ratio should be at least %.2f, but is %.2f (compressed: %d bytes, decompressed: %d bytes)",
+			ratio, actualRatio, len(compressed), len(decompressed))
+	}
+
+	return nil
+}
+
+// TheResponseShouldEchoRequestID checks whether last HTTP(s) response has header of given name with the same value,
+// as header of the same name sent in last HTTP(s) request, for example X-Request-ID.
+func (s *Scenario) TheResponseShouldEchoRequestID(headerName string) error {
+	req, err := s.lastRequest()
+	if err != nil {
+		return err
+	}
+
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	sent := req.Header.Get(headerName)
+	if sent == "" {
+		return fmt.Errorf("last HTTP(s) request does not have header '%s'", headerName)
+	}
+
+	received := lastResp.Header.Get(headerName)
+	if received != sent {
+		return fmt.Errorf("last HTTP(s) response header '%s' should echo sent value '%s', but is '%s'", headerName, sent, received)
+	}
+
+	return nil
+}
+
+// TotalRequestsSentShouldBe checks whether number of HTTP(s) requests sent during scenario is equal to n.
+// Every sent request is counted, including CORS preflights and retries. Requests are counted by TracingRequestDoer.
+func (s *Scenario) TotalRequestsSentShouldBe(n int) error {
+	trace, err := s.requestTrace()
+	if err != nil {
+		return err
+	}
+
+	if trace.RequestsSent != n {
+		return fmt.Errorf("total number of HTTP(s) requests sent during scenario should be %d, got: %d", n, trace.RequestsSent)
+	}
+
+	return nil
+}
+
+// TheResponseShouldCloseConnection checks whether server announced closing connection after last HTTP(s) response,
+// for example using header Connection: close.
+func (s *Scenario) TheResponseShouldCloseConnection() error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	if !lastResp.Close {
+		return fmt.Errorf("last HTTP(s) response should close the connection, but it keeps it alive, Connection header: '%s', protocol: %s",
+			lastResp.Header.Get("Connection"), lastResp.Proto)
+	}
+
+	return nil
+}
+
+// IEnableStructuredRequestLogging makes every following HTTP(s) request of scenario be logged by request logger,
+// by default as single line of JSON with method, URL, status and duration, written to standard error output.
+func (s *Scenario) IEnableStructuredRequestLogging() error {
+	return s.ISetStructuredLogging(true)
+}
+
+// IDisableStructuredRequestLogging stops logging following HTTP(s) requests of scenario by request logger.
+func (s *Scenario) IDisableStructuredRequestLogging() error {
+	return s.ISetStructuredLogging(false)
+}
+
+// ISetStructuredLogging turns on or off logging of following HTTP(s) requests of scenario by request logger.
+// Logging may be turned on only if requests are sent by *TracingRequestDoer with logger.
+func (s *Scenario) ISetStructuredLogging(enabled bool) error {
+	tracing, ok := s.APIContext.RequestDoer.(*TracingRequestDoer)
+	if !ok {
+		if enabled {
+			return fmt.Errorf("structured request logging requires request doer %T with logger, got: %T", tracing, s.APIContext.RequestDoer)
+		}
+
+		return nil
+	}
+
+	if enabled && tracing.Logger == nil {
+		return fmt.Errorf("structured request logging requires request doer %T with logger", tracing)
+	}
+
+	tracing.SetLoggingEnabled(enabled)
+
+	return nil
+}
+
+// IDoNotFollowRedirects makes all following HTTP(s) requests not follow redirects, so redirect response,
+// for example 302 Found, is saved as last response and its Location header may be checked.
+func (s *Scenario) IDoNotFollowRedirects() error {
+	s.modifyHTTPClient(func(client *http.Client) {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	})
+
+	return nil
+}
+
+// IForceHTTP1 makes all following HTTP(s) requests use HTTP/1.1, even if server supports HTTP/2.
+func (s *Scenario) IForceHTTP1() error {
+	transport := defaultTransport()
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	s.setTransport(transport)
+
+	return nil
+}
+
+// IForceHTTP2 makes all following HTTPS requests use HTTP/2 if server supports it.
+// Go HTTP client does not support HTTP/2 over plain text connection, so HTTP requests still use HTTP/1.1.
+func (s *Scenario) IForceHTTP2() error {
+	transport := defaultTransport()
+	transport.ForceAttemptHTTP2 = true
+	s.setTransport(transport)
+
+	return nil
+}
+
+// TheResponseProtocolShouldBe checks whether last HTTP(s) response was received using given protocol,
+// for example: HTTP/1.1 or HTTP/2.
+func (s *Scenario) TheResponseProtocolShouldBe(protocol string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	if strings.EqualFold(lastResp.Proto, protocol) {
+		return nil
+	}
+
+	if lastResp.ProtoMinor == 0 && strings.EqualFold(fmt.Sprintf("HTTP/%d", lastResp.ProtoMajor), protocol) {
+		return nil
+	}
+
+	return fmt.Errorf("last HTTP(s) response protocol should be %s, got: %s", protocol, lastResp.Proto)
+}
+
+// TheResponseShouldHaveTrailer checks whether last HTTP(s) response has trailer with given value.
+// Trailers are available only after whole response body is read, so body is drained first.
+func (s *Scenario) TheResponseShouldHaveTrailer(name, valueTemplate string) error {
+	// reading body populates trailers of last response
+	if _, err := s.APIContext.GetLastResponseBody(); err != nil {
+		return err
+	}
+
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	value, err := s.APIContext.TemplateEngine.Replace(valueTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'value' template, err: %w", err)
+	}
+
+	trailers := lastResp.Trailer.Values(name)
+	if len(trailers) == 0 {
+		available := make([]string, 0, len(lastResp.Trailer))
+		for trailerName := range lastResp.Trailer {
+			available = append(available, trailerName)
+		}
+
+		sort.Strings(available)
+
+		return fmt.Errorf("could not find trailer '%s' in last HTTP(s) response, available trailers: %v", name, available)
+	}
+
+	if trailers[0] != value {
+		return fmt.Errorf("last HTTP(s) response contains trailer '%s', but it's expected value: '%s', is not equal to actual value: '%s'", name, value, trailers[0])
+	}
+
+	return nil
+}
+
+// TheResponseShouldBeChunked checks whether last HTTP(s) response was sent using chunked transfer encoding.
+func (s *Scenario) TheResponseShouldBeChunked() error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	for _, encoding := range lastResp.TransferEncoding {
+		if strings.EqualFold(encoding, "chunked") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("last HTTP(s) response should be chunked, but has transfer encoding: %v and content length: %d",
+		lastResp.TransferEncoding, lastResp.ContentLength)
+}
+
+// TheResponseContentLengthShouldMatchBodySize checks whether Content-Length header of last HTTP(s) response
+// is equal to size of actually received body. Mismatch may reveal truncated body or misbehaving proxy.
+// Go HTTP client fails reading body shorter than Content-Length with unexpected EOF error, but last response body
+// is read ignoring it, so received part of body is compared. Body longer than Content-Length can't be detected,
+// because client cuts it to declared size.
+func (s *Scenario) TheResponseContentLengthShouldMatchBodySize() error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	header := lastResp.Header.Get("Content-Length")
+	if header == "" {
+		return fmt.Errorf("last HTTP(s) response does not have Content-Length header, transfer encoding: %v, uncompressed: %t",
+			lastResp.TransferEncoding, lastResp.Uncompressed)
+	}
+
+	contentLength, err := strconv.Atoi(header)
+	if err != nil {
+		return fmt.Errorf("last HTTP(s) response Content-Length header '%s' is not valid number, err: %w", header, err)
+	}
+
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	if contentLength != len(body) {
+		return fmt.Errorf("last HTTP(s) response Content-Length is %d, but body size is %d bytes", contentLength, len(body))
+	}
+
+	return nil
+}
+
+// TheResponseStatusCodeShouldOrShouldNotBe checks last response status code.
+func (s *Scenario) TheResponseStatusCodeShouldOrShouldNotBe(not string, code int) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertStatusCodeIsNot(code)
+	}
+
+	return s.APIContext.AssertStatusCodeIs(code)
+}
+
+// TheResponseShouldOrShouldNotHaveNode checks whether last response body contains or doesn't contain given node.
+// expr should be valid according to injected PathFinder for given data format
+func (s *Scenario) TheResponseShouldOrShouldNotHaveNode(dataFormat, not, exprTemplate string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertNodeNotExists(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	}
+
+	return s.APIContext.AssertNodeExists(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+}
+
+// TheNodeShouldBeOfValue compares node value from expression to expected by user dataValue of given by user dataType
+// Available data types are listed in switch section in each case directive.
+// expr should be valid according to injected PathFinder for provided dataFormat.
+func (s *Scenario) TheNodeShouldBeOfValue(dataFormat, exprTemplate, dataType, dataValue string) error {
+	return s.APIContext.AssertNodeIsTypeAndValue(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(dataType), dataValue)
+}
+
+// TheJSONNodesShouldBe checks many last response body JSON nodes at once. Each table row should have 3 columns:
+// node expression, data type and expected value, optional header row "path | type | value" is skipped.
+// Every row is checked the same way as in TheNodeShouldBeOfValue and all failures are reported together.
+func (s *Scenario) TheJSONNodesShouldBe(table *godog.Table) error {
+	var failures []string
+	for i, row := range table.Rows {
+		if len(row.Cells) != 3 {
+			return fmt.Errorf("table row %d should have 3 columns: path, type and value, got: %d", i+1, len(row.Cells))
+		}
+
+		path, dataType, value := row.Cells[0].Value, row.Cells[1].Value, row.Cells[2].Value
+		if i == 0 && strings.EqualFold(path, "path") && strings.EqualFold(dataType, "type") && strings.EqualFold(value, "value") {
+			continue
+		}
+
+		if err := s.TheNodeShouldBeOfValue(string(df.JSON), path, dataType, value); err != nil {
+			failures = append(failures, fmt.Sprintf("row %d: %s", i+1, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of JSON nodes are not as expected:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+
+	return nil
+}
+
+// TheNodeShouldBeOfValues compares node value from expression to expected by user one of values of given by user dataType
+// Available data types are listed in switch section in each case directive.
+// expr should be valid according to injected PathFinder for provided dataFormat.
+func (s *Scenario) TheNodeShouldBeOfValues(dataFormat, exprTemplate, dataType, valuesTemplates string) error {
+	return s.APIContext.AssertNodeIsTypeAndHasOneOfValues(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(dataType), valuesTemplates)
+}
+
+// TheNodeShouldOrShouldNotContainSubString checks whether value of last HTTP response node, obtained using exprTemplate
+// is string type and contains/doesn't contain given substring
+func (s *Scenario) TheNodeShouldOrShouldNotContainSubString(dataFormat, exprTemplate, not, subTemplate string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertNodeNotContainsSubString(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, subTemplate)
+	}
+
+	return s.APIContext.AssertNodeContainsSubString(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, subTemplate)
+}
+
+// TheNodeShouldOrShouldNotBeSliceOfLength checks whether given key is slice and has/hasn't given length
+// expr should be valid according to injected PathFinder for provided dataFormat
+func (s *Scenario) TheNodeShouldOrShouldNotBeSliceOfLength(dataFormat, exprTemplate, not string, length int) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertNodeSliceLengthIsNot(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, length)
+	}
+
+	return s.APIContext.AssertNodeSliceLengthIs(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, length)
+}
+
+// TheNodeLengthShouldBe checks whether last response body node has given length.
+// Length of string is number of its characters, length of slice is number of its elements
+// and length of map is number of its keys.
+func (s *Scenario) TheNodeLengthShouldBe(dataFormat, exprTemplate string, length int) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	var nodeLength int
+	var measure string
+	switch v := reflect.ValueOf(node); v.Kind() {
+	case reflect.String:
+		nodeLength, measure = utf8.RuneCountInString(v.String()), "string"
+	case reflect.Slice, reflect.Array:
+		nodeLength, measure = v.Len(), "slice"
+	case reflect.Map:
+		nodeLength, measure = v.Len(), "map"
+	default:
+		return fmt.Errorf("node '%s' should be string, slice or map, got: %T", exprTemplate, node)
+	}
+
+	if nodeLength != length {
+		return fmt.Errorf("node '%s' is %s of length %d, expected length %d", exprTemplate, measure, nodeLength, length)
+	}
+
+	return nil
+}
+
+// TheNodeSliceLengthShouldBeGreaterThanCached checks whether last response body node is slice having more elements
+// than number saved in cache under given key, for example by ISaveNodeSliceLengthAs.
+func (s *Scenario) TheNodeSliceLengthShouldBeGreaterThanCached(dataFormat, exprTemplate, cacheKey string) error {
+	cached, err := s.APIContext.Cache.GetSaved(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	cachedLength, ok := cached.(int)
+	if !ok {
+		return fmt.Errorf("value under cache key '%s' should be int, got: %T", cacheKey, cached)
+	}
+
+	length, err := s.lastResponseNodeSliceLength(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	if length <= cachedLength {
+		return fmt.Errorf("node '%s' is slice of length %d, expected length greater than cached '%s': %d", exprTemplate, length, cacheKey, cachedLength)
+	}
+
+	return nil
+}
+
+// TheNodeStringShouldBeInCharset checks whether last response body node is string containing only characters
+// from provided charset. Charsets are the same as used by random data generation methods.
+func (s *Scenario) TheNodeStringShouldBeInCharset(dataFormat, exprTemplate, charset string) error {
+	charsetRunes, err := charsetByName(charset)
+	if err != nil {
+		return err
+	}
+
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	str, ok := node.(string)
+	if !ok {
+		return fmt.Errorf("node '%s' should be string, got: %T", exprTemplate, node)
+	}
+
+	for i, r := range []rune(str) {
+		if !strings.ContainsRune(charsetRunes, r) {
+			return fmt.Errorf("node '%s' value '%s' should only contain %s characters, but contains '%c' at index %d", exprTemplate, str, charset, r, i)
+		}
+	}
+
+	return nil
+}
+
+// TheNodeShouldOrShouldNotBe checks whether node from last response body is/is not of provided type
+// goType may be one of: nil, string, int, float, bool, map, slice
+// expr should be valid according to injected PathResolver.
+func (s *Scenario) TheNodeShouldOrShouldNotBe(dataFormat, exprTemplate, not, goType string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertNodeIsNotType(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(goType))
+	}
+
+	return s.APIContext.AssertNodeIsType(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, types.DataType(goType))
+}
+
+// TheNodeNumberShouldNotUseScientificNotation checks whether last response body node is number
+// written without scientific notation, for example 100000 instead of 1e5.
+func (s *Scenario) TheNodeNumberShouldNotUseScientificNotation(dataFormat, exprTemplate string) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	expr, err := s.APIContext.TemplateEngine.Replace(exprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	token, err := rawNumberToken(df.DataFormat(strings.ToLower(dataFormat)), expr, body)
+	if err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(token, "eE") {
+		return fmt.Errorf("node '%s' should not use scientific notation, got: %s", expr, token)
+	}
+
+	return nil
+}
+
+// TheNodeShouldBeMasked checks whether last response body node is string with masked value,
+// according to Scenario.MaskingRegExp or DefaultMaskingRegExp if it is not set.
+func (s *Scenario) TheNodeShouldBeMasked(dataFormat, exprTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	str, ok := node.(string)
+	if !ok {
+		return fmt.Errorf("node '%s' should be masked string, got: %#v (%T)", exprTemplate, node, node)
+	}
+
+	maskingRegExp := s.MaskingRegExp
+	if maskingRegExp == nil {
+		maskingRegExp = DefaultMaskingRegExp
+	}
+
+	if !maskingRegExp.MatchString(str) {
+		return fmt.Errorf("node '%s' should be masked according to regExp '%s', got: '%s'", exprTemplate, maskingRegExp, str)
+	}
+
+	return nil
+}
+
+// TheNodeBase64DecodedShouldEqual checks whether last response body node is base64 encoded string,
+// which decoded content equals expected value. Standard and URL-safe encodings, with or without padding, are accepted.
+// expectedTemplate may contain template values.
+func (s *Scenario) TheNodeBase64DecodedShouldEqual(dataFormat, exprTemplate, expectedTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	str, ok := node.(string)
+	if !ok {
+		return fmt.Errorf("node '%s' should be base64 encoded string, got: %#v (%T)", exprTemplate, node, node)
+	}
+
+	expected, err := s.APIContext.TemplateEngine.Replace(expectedTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expected' template, err: %w", err)
+	}
+
+	var decoded []byte
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if decoded, err = encoding.DecodeString(strings.TrimSpace(str)); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("node '%s' should be valid base64 encoded string, got: '%s', err: %w", exprTemplate, str, err)
+	}
+
+	if string(decoded) != expected {
+		return fmt.Errorf("node '%s' base64 decoded should equal '%s', got: '%s'", exprTemplate, expected, decoded)
+	}
+
+	return nil
+}
+
+// TheNodeShouldBeSemVer checks whether last response body node is string with valid semantic version,
+// for example: 1.2.3 or 2.0.0-rc.1+build.5
+func (s *Scenario) TheNodeShouldBeSemVer(dataFormat, exprTemplate string) error {
+	_, err := s.lastResponseNodeSemVer(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+
+	return err
+}
+
+// TheNodeSemVerShouldSatisfy checks whether last response body node is semantic version satisfying
+// all comma separated constraints, for example: ">=1.2.0, <2.0.0". Pre-releases have lower precedence than releases.
+func (s *Scenario) TheNodeSemVerShouldSatisfy(dataFormat, exprTemplate, constraintsTemplate string) error {
+	version, err := s.lastResponseNodeSemVer(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	constraints, err := s.APIContext.TemplateEngine.Replace(constraintsTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'constraints' template, err: %w", err)
+	}
+
+	ok, err := satisfiesSemVerConstraints(version, constraints)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("node '%s' version %s should satisfy '%s', but it doesn't", exprTemplate, version.raw, constraints)
+	}
+
+	return nil
+}
+
+// TheNodeSignShouldBe checks whether last response body node is number of given sign:
+// positive, negative, zero, non-negative or non-positive. XML node text is parsed as number.
+func (s *Scenario) TheNodeSignShouldBe(dataFormat, exprTemplate, sign string) error {
+	format := df.DataFormat(strings.ToLower(dataFormat))
+	node, err := s.lastResponseNode(format, exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	// XML has no number type, so node text is parsed instead
+	if text, isText := node.(string); isText && format == df.XML {
+		if node, err = strconv.ParseFloat(strings.TrimSpace(text), 64); err != nil {
+			return fmt.Errorf("node '%s' should be number, could not parse '%s'", exprTemplate, text)
+		}
+	}
+
+	number, isNumber := nodeNumber(node)
+	if !isNumber {
+		return fmt.Errorf("node '%s' should be number, got: %T", exprTemplate, node)
+	}
+
+	var ok bool
+	switch sign {
+	case "positive":
+		ok = number > 0
+	case "negative":
+		ok = number < 0
+	case "zero":
+		ok = number == 0
+	case "non-negative":
+		ok = number >= 0
+	case "non-positive":
+		ok = number <= 0
+	default:
+		return fmt.Errorf("unknown sign '%s', available: positive, negative, zero, non-negative, non-positive", sign)
+	}
+
+	if !ok {
+		return fmt.Errorf("node '%s' should be %s, got: %v", exprTemplate, sign, node)
+	}
+
+	return nil
+}
+
+/*
+TheNodeShouldBeInSchemaEnum checks whether last response body node is one of values listed in enum of JSON schema.
+Argument "schemaRef" may be URL or full/relative path to schema, the same as in schema validation methods.
+Argument "schemaPointer" is JSON pointer to schema with "enum" keyword, for example: /properties/status
+*/
+func (s *Scenario) TheNodeShouldBeInSchemaEnum(dataFormat, exprTemplate, schemaPointer, schemaRefTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	schemaRef, err := s.APIContext.TemplateEngine.Replace(schemaRefTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'schema' template, err: %w", err)
+	}
+
+	enum, err := s.schemaEnum(schemaRef, schemaPointer)
+	if err != nil {
+		return err
+	}
+
+	actual, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	for _, value := range enum {
+		if reflect.DeepEqual(value, actual) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("node '%s' should be one of %s, got: %s", exprTemplate, toJSON(enum), toJSON(actual))
+}
+
+// TheNodeShouldEqualExpression checks whether last response body node is number equal to result of arithmetic
+// expression, for example: {{.PRICE}} * {{.QTY}}. Expression may contain numbers, parentheses and operators + - * /.
+// Numbers are compared with small tolerance to ignore floating point rounding errors.
+func (s *Scenario) TheNodeShouldEqualExpression(dataFormat, exprTemplate, arithmeticTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	actual, isNumber := nodeNumber(node)
+	if !isNumber {
+		return fmt.Errorf("node '%s' should be number, got: %T", exprTemplate, node)
+	}
+
+	arithmetic, err := s.APIContext.TemplateEngine.Replace(arithmeticTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	expected, err := evalArithmetic(arithmetic)
+	if err != nil {
+		return err
+	}
+
+	if math.Abs(actual-expected) > 1e-9*math.Max(1, math.Abs(expected)) {
+		return fmt.Errorf("node '%s' should equal %s = %v, got: %v", exprTemplate, arithmetic, expected, actual)
+	}
+
+	return nil
+}
+
+// TheDifferenceBetweenNodesShouldBe checks whether difference A - B between last response body numeric nodes
+// is equal to expected value, within small tolerance for floating point errors.
+func (s *Scenario) TheDifferenceBetweenNodesShouldBe(dataFormat, exprATemplate, exprBTemplate string, expected float64) error {
+	format := df.DataFormat(strings.ToLower(dataFormat))
+	nodeA, err := s.lastResponseNode(format, exprATemplate)
+	if err != nil {
+		return err
+	}
+
+	nodeB, err := s.lastResponseNode(format, exprBTemplate)
+	if err != nil {
+		return err
+	}
+
+	a, isNumber := nodeNumber(nodeA)
+	if !isNumber {
+		return fmt.Errorf("node '%s' should be number, got: %T", exprATemplate, nodeA)
+	}
+
+	b, isNumber := nodeNumber(nodeB)
+	if !isNumber {
+		return fmt.Errorf("node '%s' should be number, got: %T", exprBTemplate, nodeB)
+	}
+
+	if diff := a - b; math.Abs(diff-expected) > 1e-9*math.Max(1, math.Abs(expected)) {
+		return fmt.Errorf("difference between nodes '%s' and '%s' should be %v, got: %v - %v = %v", exprATemplate, exprBTemplate, expected, a, b, diff)
+	}
+
+	return nil
+}
+
+// TheNodeShouldMatchFormat checks whether last response body node equals string built by fmt.Sprintf
+// from format and cached values, which keys are listed in argKeys, for example: "USER_ID, NAME".
+// Numbers saved from JSON are float64, so use %v verb to format them.
+func (s *Scenario) TheNodeShouldMatchFormat(dataFormat, exprTemplate, formatTemplate, argKeys string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	format, err := s.APIContext.TemplateEngine.Replace(formatTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'format' template, err: %w", err)
+	}
+
+	var args []any
+	for _, key := range strings.Split(argKeys, ",") {
+		if key = strings.TrimSpace(key); key == "" {
+			continue
+		}
+
+		arg, err := s.APIContext.Cache.GetSaved(key)
+		if err != nil {
+			return fmt.Errorf("could not obtain format argument, err: %w", err)
+		}
+
+		args = append(args, arg)
+	}
+
+	expected := fmt.Sprintf(format, args...)
+	actual, ok := node.(string)
+	if !ok {
+		actual = fmt.Sprint(node)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("node '%s' should be '%s', got: '%s'", exprTemplate, expected, actual)
+	}
+
+	return nil
+}
+
+// TheNodeShouldBeJSONNull checks whether last response body contains given node and its value is null.
+// Missing node and node with other value are reported separately.
+func (s *Scenario) TheNodeShouldBeJSONNull(dataFormat, exprTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		if isNodeNotFound(err) {
+			return fmt.Errorf("node '%s' should exist and be null, but it is missing, err: %w", exprTemplate, err)
+		}
+
+		return err
+	}
+
+	if node != nil {
+		return fmt.Errorf("node '%s' exists, but it should be null, got: %s", exprTemplate, toJSON(node))
+	}
+
+	return nil
+}
+
+// TheNodeShouldExistAndNotBeNull checks whether last response body contains given node and its value is not null.
+// Missing node and null node are reported separately.
+func (s *Scenario) TheNodeShouldExistAndNotBeNull(dataFormat, exprTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		if isNodeNotFound(err) {
+			return fmt.Errorf("node '%s' should exist, but it is missing, err: %w", exprTemplate, err)
+		}
+
+		return err
+	}
+
+	if node == nil {
+		return fmt.Errorf("node '%s' exists, but it should not be null", exprTemplate)
+	}
+
+	return nil
+}
+
+// TheNodeShouldBeBool checks whether last response body node is boolean of provided value: "true" or "false".
+// String "true" is not treated as boolean true. XML has no boolean type, so XML node text is compared instead.
+func (s *Scenario) TheNodeShouldBeBool(dataFormat, exprTemplate, expected string) error {
+	format := df.DataFormat(strings.ToLower(dataFormat))
+	node, err := s.lastResponseNode(format, exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	if format == df.XML {
+		if node != expected {
+			return fmt.Errorf("node '%s' should be %s, got: %#v", exprTemplate, expected, node)
+		}
+
+		return nil
+	}
+
+	switch v := node.(type) {
+	case bool:
+		if strconv.FormatBool(v) != expected {
+			return fmt.Errorf("node '%s' should be %s, got: %t", exprTemplate, expected, v)
+		}
+
+		return nil
+	case string:
+		return fmt.Errorf("node '%s' should be boolean %s, got string %q", exprTemplate, expected, v)
+	default:
+		return fmt.Errorf("node '%s' should be boolean %s, got: %#v (%T)", exprTemplate, expected, node, node)
+	}
+}
+
+// TheNodeBoolShouldBeOppositeOfCached checks whether last response body node, which should be boolean,
+// is negation of boolean saved in cache under cacheKey, for example using ISaveNodeBoolAs method.
+func (s *Scenario) TheNodeBoolShouldBeOppositeOfCached(dataFormat, exprTemplate, cacheKey string) error {
+	actual, err := s.lastResponseNodeBool(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	cached, err := s.APIContext.Cache.GetSaved(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain cached value, err: %w", err)
+	}
+
+	cachedBool, ok := cached.(bool)
+	if !ok {
+		return fmt.Errorf("cached value '%s' should be boolean, got: %T", cacheKey, cached)
+	}
+
+	if actual == cachedBool {
+		return fmt.Errorf("node '%s' should be %t, opposite of cached '%s' equal to %t, got: %t", exprTemplate, !cachedBool, cacheKey, cachedBool, actual)
+	}
+
+	return nil
+}
+
+/*
+TheNodeDateShouldBeInThe checks whether last response body node, parsed as date according to provided layout,
+is in the past or in the future in relation to current time.
+layout should be valid layout for golang standard library time.Parse func, for example: 2006-01-02T15:04:05Z07:00
+*/
+func (s *Scenario) TheNodeDateShouldBeInThe(dataFormat, exprTemplate, layout, when string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	dateStr, ok := node.(string)
+	if !ok {
+		return fmt.Errorf("node '%s' should be string, got: %T", exprTemplate, node)
+	}
+
+	date, err := time.Parse(layout, dateStr)
+	if err != nil {
+		return fmt.Errorf("could not parse node '%s' value '%s' using layout '%s', err: %w", exprTemplate, dateStr, layout, err)
+	}
+
+	now := time.Now()
+	switch when {
+	case "past":
+		if !date.Before(now) {
+			return fmt.Errorf("node '%s' date %s should be in the past, now is %s", exprTemplate, date.Format(time.RFC3339), now.Format(time.RFC3339))
+		}
+	case "future":
+		if !date.After(now) {
+			return fmt.Errorf("node '%s' date %s should be in the future, now is %s", exprTemplate, date.Format(time.RFC3339), now.Format(time.RFC3339))
+		}
+	default:
+		return fmt.Errorf("unknown time direction '%s', available: past, future", when)
+	}
+
+	return nil
+}
+
+// TheNodeShouldEqualCachedBody checks whether last response body node is equal to JSON body saved in cache
+// under cacheKey, for example using ISaveLastResponseBodyAs method. Comparison is semantic, not byte by byte.
+func (s *Scenario) TheNodeShouldEqualCachedBody(dataFormat, exprTemplate, cacheKey string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	cachedBody, err := s.cachedJSON(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	actual, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	if diffs := jsonDiff("$", cachedBody, actual); len(diffs) > 0 {
+		return fmt.Errorf("node '%s' is not equal to cached body '%s', differences:\n%s", exprTemplate, cacheKey, strings.Join(diffs, "\n"))
+	}
+
+	return nil
+}
+
+// TheNodeShouldEqualTransformedCache checks whether last response body node is equal to string value saved in cache
+// under cacheKey after applying transform. Available transforms: upper, lower, trim, base64 and md5 (hex encoded).
+func (s *Scenario) TheNodeShouldEqualTransformedCache(dataFormat, exprTemplate, cacheKey, transform string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	value, err := s.cachedString(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	var expected string
+	switch transform {
+	case "upper":
+		expected = strings.ToUpper(value)
+	case "lower":
+		expected = strings.ToLower(value)
+	case "trim":
+		expected = strings.TrimSpace(value)
+	case "base64":
+		expected = base64.StdEncoding.EncodeToString([]byte(value))
+	case "md5":
+		sum := md5.Sum([]byte(value))
+		expected = hex.EncodeToString(sum[:])
+	default:
+		return fmt.Errorf("unknown transform '%s', available: upper, lower, trim, base64, md5", transform)
+	}
+
+	if node != expected {
+		return fmt.Errorf("node '%s' should be '%s' (cached '%s' transformed by %s), got: %#v", exprTemplate, expected, cacheKey, transform, node)
+	}
+
+	return nil
+}
+
+// EachNodeSliceElementShouldHaveKeys checks whether every element of last response body node, which should be slice,
+// is object having all keys listed in keysCSV, for example: "id, name". First element missing any key is reported.
+func (s *Scenario) EachNodeSliceElementShouldHaveKeys(dataFormat, sliceExprTemplate, keysCSV string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), sliceExprTemplate)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", sliceExprTemplate, err)
+	}
+
+	elements, ok := normalized.([]any)
+	if !ok {
+		return fmt.Errorf("node '%s' should be slice, got: %T", sliceExprTemplate, node)
+	}
+
+	var keys []string
+	for _, key := range strings.Split(keysCSV, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	for i, element := range elements {
+		object, ok := element.(map[string]any)
+		if !ok {
+			return fmt.Errorf("element %d of node '%s' should be object, got: %s", i, sliceExprTemplate, toJSON(element))
+		}
+
+		for _, key := range keys {
+			if _, ok = object[key]; !ok {
+				return fmt.Errorf("element %d of node '%s' is missing key '%s', element: %s", i, sliceExprTemplate, key, toJSON(element))
+			}
+		}
+	}
+
+	return nil
+}
+
+// TheNodeSliceElementsShouldAllBeType checks whether every element of last response body node slice is of given Go type:
+// string, int, float, bool, map or slice. Every number is float, but only number without fractional part is int.
+func (s *Scenario) TheNodeSliceElementsShouldAllBeType(dataFormat, sliceExprTemplate, goType string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), sliceExprTemplate)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", sliceExprTemplate, err)
+	}
+
+	elements, ok := normalized.([]any)
+	if !ok {
+		return fmt.Errorf("node '%s' should be slice, got: %T", sliceExprTemplate, node)
+	}
+
+	for i, element := range elements {
+		kind := goKind(element)
+		if kind == goType || goType == "float" && kind == "int" {
+			continue
+		}
+
+		return fmt.Errorf("every element of node '%s' should be %s, but element %d is %s: %s", sliceExprTemplate, goType, i, kind, toJSON(element))
+	}
+
+	return nil
+}
+
+// TheNodeSliceShouldBePermutationOfCached checks whether last response body node is slice containing the same
+// elements as slice saved in cache under cacheKey, in any order. Elements are compared as multisets,
+// so number of occurrences of each element must match too.
+func (s *Scenario) TheNodeSliceShouldBePermutationOfCached(dataFormat, exprTemplate, cacheKey string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	actual, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	actualSlice, ok := actual.([]any)
+	if !ok {
+		return fmt.Errorf("node '%s' should be slice, got: %T", exprTemplate, node)
+	}
+
+	cached, err := s.cachedJSON(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	cachedSlice, ok := cached.([]any)
+	if !ok {
+		return fmt.Errorf("value saved under key '%s' should be slice, got: %s", cacheKey, toJSON(cached))
+	}
+
+	// elements are counted by their JSON representation, which has object keys sorted
+	counts := map[string]int{}
+	for _, element := range cachedSlice {
+		counts[toJSON(element)]++
+	}
+
+	for _, element := range actualSlice {
+		counts[toJSON(element)]--
+	}
+
+	var missing, extra []string
+	for element, count := range counts {
+		for ; count > 0; count-- {
+			missing = append(missing, element)
+		}
+
+		for ; count < 0; count++ {
+			extra = append(extra, element)
+		}
+	}
+
+	if len(missing) > 0 || len(extra) > 0 {
+		sort.Strings(missing)
+		sort.Strings(extra)
+
+		return fmt.Errorf("node '%s' should be permutation of cached '%s', missing elements: [%s], extra elements: [%s]",
+			exprTemplate, cacheKey, strings.Join(missing, ", "), strings.Join(extra, ", "))
+	}
+
+	return nil
+}
+
+// ICollectNodeItemsInto appends elements of last response body node slice to slice saved in cache under collectKey,
+// creating it if necessary. It may be used to accumulate items of consecutive pages of paginated endpoint.
+func (s *Scenario) ICollectNodeItemsInto(dataFormat, exprTemplate, collectKey string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	items, ok := normalized.([]any)
+	if !ok {
+		return fmt.Errorf("node '%s' should be slice, got: %T", exprTemplate, node)
+	}
+
+	var collected []any
+	if saved, err := s.APIContext.Cache.GetSaved(collectKey); err == nil {
+		if collected, ok = saved.([]any); !ok {
+			return fmt.Errorf("value saved under key '%s' should be slice of collected items, got: %T", collectKey, saved)
+		}
+	}
+
+	s.APIContext.Cache.Save(collectKey, append(collected, items...))
+
+	return nil
+}
+
+// CollectedPaginationItemsShouldHaveNoDuplicates checks whether items collected under collectKey, for example from
+// consecutive pages of paginated endpoint, have unique identifiers. Identifier of each item is obtained
+// using JSON path expression idExprTemplate, for example: id. Duplicates usually mean that pages overlap.
+func (s *Scenario) CollectedPaginationItemsShouldHaveNoDuplicates(collectKey, idExprTemplate string) error {
+	cached, err := s.cachedJSON(collectKey)
+	if err != nil {
+		return err
+	}
+
+	items, ok := cached.([]any)
+	if !ok {
+		return fmt.Errorf("value saved under key '%s' should be slice of collected items, got: %s", collectKey, toJSON(cached))
+	}
+
+	idExpr, err := s.APIContext.TemplateEngine.Replace(idExprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'id expression' template, err: %w", err)
+	}
+
+	seen := map[string]int{}
+	for i, item := range items {
+		id, err := s.findNode(df.JSON, idExpr, []byte(toJSON(item)))
+		if err != nil {
+			return fmt.Errorf("problem with collected item %d: %s, err: %w", i, toJSON(item), err)
+		}
+
+		key := toJSON(id)
+		if first, ok := seen[key]; ok {
+			return fmt.Errorf("collected items '%s' should have no duplicate '%s', but %s is duplicated at items %d and %d (of %d collected)",
+				collectKey, idExpr, key, first, i, len(items))
+		}
+
+		seen[key] = i
+	}
+
+	return nil
+}
+
+// TheNodeShouldEqualNodeFromCachedResponse checks whether last response body node is equal to node
+// of response saved in cache under cachedRespKey, for example using ISaveLastResponseAs method.
+// Comparison is semantic, not byte by byte.
+func (s *Scenario) TheNodeShouldEqualNodeFromCachedResponse(dataFormat, exprTemplate, cachedExprTemplate, cachedRespKey string) error {
+	format := df.DataFormat(strings.ToLower(dataFormat))
+	node, err := s.lastResponseNode(format, exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	cachedResp, err := s.cachedResponse(cachedRespKey)
+	if err != nil {
+		return err
+	}
+
+	cachedExpr, err := s.APIContext.TemplateEngine.Replace(cachedExprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	cachedNode, err := s.findNode(format, cachedExpr, []byte(cachedResp.Body))
+	if err != nil {
+		return fmt.Errorf("problem with cached response '%s', err: %w", cachedRespKey, err)
+	}
+
+	actual, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	expected, err := normalizeJSON(cachedNode)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s' of cached response '%s', err: %w", cachedExpr, cachedRespKey, err)
+	}
+
+	if diffs := jsonDiff("$", expected, actual); len(diffs) > 0 {
+		return fmt.Errorf("node '%s' is not equal to node '%s' of cached response '%s', differences:\n%s",
+			exprTemplate, cachedExpr, cachedRespKey, strings.Join(diffs, "\n"))
+	}
+
+	return nil
+}
+
+// TheNodeShouldBeAbsentComparedToCachedResponse checks whether node exists in response saved in cache
+// under cachedRespKey, for example using ISaveLastResponseAs method, but is absent in last response body.
+// It is useful for verifying that update removed a field.
+func (s *Scenario) TheNodeShouldBeAbsentComparedToCachedResponse(dataFormat, exprTemplate, cachedRespKey string) error {
+	format := df.DataFormat(strings.ToLower(dataFormat))
+	cachedResp, err := s.cachedResponse(cachedRespKey)
+	if err != nil {
+		return err
+	}
+
+	expr, err := s.APIContext.TemplateEngine.Replace(exprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	if _, err = s.findNode(format, expr, []byte(cachedResp.Body)); err != nil {
+		if isNodeNotFound(err) {
+			return fmt.Errorf("node '%s' should exist in cached response '%s', but it is missing, err: %w", expr, cachedRespKey, err)
+		}
+
+		return err
+	}
+
+	node, err := s.lastResponseNode(format, exprTemplate)
+	if err == nil {
+		return fmt.Errorf("node '%s' should be absent in last response body, but it exists with value: %s", expr, toJSON(node))
+	}
+
+	if !isNodeNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// TheResponseNodeShouldEqualSentBody checks whether last response body node is equal to body of last sent request.
+// Comparison is semantic, not byte by byte.
+func (s *Scenario) TheResponseNodeShouldEqualSentBody(dataFormat, exprTemplate string) error {
+	node, err := s.lastResponseNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	trace, err := s.lastRequestTrace()
+	if err != nil {
+		return err
+	}
+
+	var sentBody any
+	if err = json.Unmarshal(trace.LastRequestBody, &sentBody); err != nil {
+		return fmt.Errorf("last sent request body is not valid JSON, err: %w", err)
+	}
+
+	actual, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	if diffs := jsonDiff("$", sentBody, actual); len(diffs) > 0 {
+		return fmt.Errorf("node '%s' is not equal to sent request body, differences:\n%s", exprTemplate, strings.Join(diffs, "\n"))
+	}
+
+	return nil
+}
+
+// TheResponseShouldBeJSONArrayOfLength checks whether last response body is JSON with array of given length at root.
+func (s *Scenario) TheResponseShouldBeJSONArrayOfLength(length int) error {
+	root, err := s.lastResponseJSONRoot()
+	if err != nil {
+		return err
+	}
+
+	array, ok := root.([]any)
+	if !ok {
+		return fmt.Errorf("last response body should be JSON array, got: %s", jsonKind(root))
+	}
+
+	if len(array) != length {
+		return fmt.Errorf("last response body should be JSON array of length %d, got length: %d", length, len(array))
+	}
+
+	return nil
+}
+
+// TheResponseShouldBeJSONObject checks whether last response body is JSON with object at root.
+func (s *Scenario) TheResponseShouldBeJSONObject() error {
+	root, err := s.lastResponseJSONRoot()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := root.(map[string]any); !ok {
+		return fmt.Errorf("last response body should be JSON object, got: %s", jsonKind(root))
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldContainJSON checks whether last response body, which should be JSON, contains all nodes
+// from expected JSON with the same values. Additional nodes of last response body are ignored.
+func (s *Scenario) TheResponseBodyShouldContainJSON(partial *godog.DocString) error {
+	root, err := s.lastResponseJSONRoot()
+	if err != nil {
+		return err
+	}
+
+	partialContent, err := s.APIContext.TemplateEngine.Replace(partial.Content, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'partial' template, err: %w", err)
+	}
+
+	var expected any
+	if err = json.Unmarshal([]byte(partialContent), &expected); err != nil {
+		return fmt.Errorf("expected partial JSON is invalid, err: %w", err)
+	}
+
+	if mismatch := jsonSubsetMismatch("$", expected, root); mismatch != "" {
+		return fmt.Errorf("last response body does not contain expected JSON, first difference:\n%s", mismatch)
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldMatchShape checks whether last response body has the same structure as provided shape,
+// which is JSON with type names as leaves, for example: {"id": "int", "tags": ["string"], "owner": "any"}.
+// Available type names: string, int, float, bool, map, slice, nil, any. Every number is float,
+// but only number without fractional part is int. Single element of shape array describes every element of array.
+func (s *Scenario) TheResponseBodyShouldMatchShape(shape *godog.DocString) error {
+	root, err := s.lastResponseJSONRoot()
+	if err != nil {
+		return err
+	}
+
+	var expected any
+	if err = json.Unmarshal([]byte(shape.Content), &expected); err != nil {
+		return fmt.Errorf("expected shape is not valid JSON, err: %w", err)
+	}
+
+	mismatch, err := jsonShapeMismatch("$", expected, root)
+	if err != nil {
+		return fmt.Errorf("expected shape is invalid, err: %w", err)
+	}
+
+	if mismatch != "" {
+		return fmt.Errorf("last response body does not match expected shape, first difference:\n%s", mismatch)
+	}
+
+	return nil
+}
+
+// TheNDJSONResponseShouldHaveLines checks whether last response body is NDJSON stream of exactly n JSON values.
+// Each non-empty line should be valid JSON, empty lines, for example trailing one, are skipped.
+func (s *Scenario) TheNDJSONResponseShouldHaveLines(n int) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	count := 0
+	for i, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		if !json.Valid(line) {
+			return fmt.Errorf("last response body line %d is not valid JSON: %s", i+1, snippet(line, 0))
+		}
+
+		count++
+	}
+
+	if count != n {
+		return fmt.Errorf("last response body should have %d NDJSON objects, got: %d", n, count)
+	}
+
+	return nil
+}
+
+// TheResponseShouldHaveNodeAtPointer checks whether last response body, which should be JSON,
+// has node at given RFC 6901 JSON pointer, for example: /data/0/id
+func (s *Scenario) TheResponseShouldHaveNodeAtPointer(pointerTemplate string) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	pointerStr, err := s.APIContext.TemplateEngine.Replace(pointerTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'pointer' template, err: %w", err)
+	}
+
+	var data any
+	if err = json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("last response body is not valid JSON, err: %w", err)
+	}
+
+	pointer, err := gojsonpointer.NewJsonPointer(pointerStr)
+	if err != nil {
+		return fmt.Errorf("invalid JSON pointer '%s', err: %w", pointerStr, err)
+	}
+
+	if _, _, err = pointer.Get(data); err != nil {
+		return fmt.Errorf("last response body should have node at pointer '%s', err: %w", pointerStr, err)
+	}
+
+	return nil
+}
+
+// TheCachedJSONValueShouldHaveNode checks whether JSON saved in cache under cacheKey has given node.
+// Cached value may be JSON string, JSON bytes or already deserialized data.
+func (s *Scenario) TheCachedJSONValueShouldHaveNode(cacheKey, exprTemplate string) error {
+	data, err := s.cachedJSON(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	expr, err := s.APIContext.TemplateEngine.Replace(exprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("could not serialize value saved under key '%s', err: %w", cacheKey, err)
+	}
+
+	if _, err = s.findNode(df.JSON, expr, dataBytes); err != nil {
+		return fmt.Errorf("cached JSON '%s' does not have expected node, err: %w", cacheKey, err)
+	}
+
+	return nil
+}
+
+// TheResponseShouldHaveNodes checks whether last request body has keys defined in string separated by comma
+// nodeExpr should be valid according to injected PathFinder expressions separated by comma (,)
+func (s *Scenario) TheResponseShouldHaveNodes(dataFormat, nodesExpr string) error {
+	return s.APIContext.AssertNodesExist(df.DataFormat(strings.ToLower(dataFormat)), nodesExpr)
+}
+
+// TheNodeShouldOrShouldNotMatchRegExp checks whether last response body node matches or doesn't match provided regExp.
+func (s *Scenario) TheNodeShouldOrShouldNotMatchRegExp(dataFormat, exprTemplate, not, regExpTemplate string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertNodeNotMatchesRegExp(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, regExpTemplate)
+	}
+
+	return s.APIContext.AssertNodeMatchesRegExp(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, regExpTemplate)
+}
+
+// TheResponseBodyChecksumShouldBe checks whether digest of raw last response body, computed using given algorithm:
+// sha256, sha1 or md5, is equal to expected hex encoded digest.
+func (s *Scenario) TheResponseBodyChecksumShouldBe(algorithm, expectedTemplate string) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	expected, err := s.APIContext.TemplateEngine.Replace(expectedTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'checksum' template, err: %w", err)
+	}
+
+	var digest []byte
+	switch algorithm {
+	case "sha256":
+		sum := sha256.Sum256(body)
+		digest = sum[:]
+	case "sha1":
+		sum := sha1.Sum(body)
+		digest = sum[:]
+	case "md5":
+		sum := md5.Sum(body)
+		digest = sum[:]
+	default:
+		return fmt.Errorf("unknown algorithm '%s', available: sha256, sha1, md5", algorithm)
+	}
+
+	actual := hex.EncodeToString(digest)
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("last response body %s checksum should be %s, but is %s", algorithm, expected, actual)
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldBeValidHTML checks whether last response body is non-empty HTML document
+// with properly nested and closed elements. Elements which end tags are optional, like <p> or <li>, may stay open.
+func (s *Scenario) TheResponseBodyShouldBeValidHTML() error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not parse last response body as HTML, err: %w", err)
+	}
+
+	if !htmlHasContent(doc) {
+		return errors.New("last response body should be valid HTML, but it is empty document")
+	}
+
+	issues, err := htmlIssues(body)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("last response body should be valid HTML, found issues:\n%s", strings.Join(issues, "\n"))
+	}
+
+	return nil
+}
+
+// TheResponseHTMLShouldHaveElementsMatching checks whether last response body, which should be HTML,
+// has exactly count elements matching CSS selector, for example: "ul#menu > li.item a[href^='https']".
+// Selector should have syntax acceptable by https://github.com/andybalholm/cascadia library.
+func (s *Scenario) TheResponseHTMLShouldHaveElementsMatching(count int, selectorTemplate string) error {
+	selector, elements, err := s.lastResponseHTMLElements(selectorTemplate)
+	if err != nil {
+		return err
+	}
+
+	if len(elements) != count {
+		return fmt.Errorf("last response HTML should have %d elements matching '%s', got: %d", count, selector, len(elements))
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldBeValidUTF8 checks whether last response body is valid UTF-8 encoded text.
+func (s *Scenario) TheResponseBodyShouldBeValidUTF8() error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	if utf8.Valid(body) {
+		return nil
+	}
+
+	offset := 0
+	for offset < len(body) {
+		r, size := utf8.DecodeRune(body[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+
+		offset += size
+	}
+
+	return fmt.Errorf("last response body should be valid UTF-8, but has invalid byte sequence at offset %d: %s", offset, snippet(body, offset))
+}
+
+// TheResponseBodyShouldStartWithBytes checks whether last HTTP(s) response body starts with bytes given as hex string,
+// for example file signature (magic bytes) like 89504E47 for PNG. Whitespaces in hexPrefix are ignored.
+func (s *Scenario) TheResponseBodyShouldStartWithBytes(hexPrefix string) error {
+	prefix, err := hex.DecodeString(strings.Join(strings.Fields(hexPrefix), ""))
+	if err != nil {
+		return fmt.Errorf("'%s' is not valid hex string, err: %w", hexPrefix, err)
+	}
+
+	if len(prefix) == 0 {
+		return errors.New("bytes prefix should not be empty")
+	}
+
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	if bytes.HasPrefix(body, prefix) {
+		return nil
+	}
+
+	leading := body
+	if len(leading) > len(prefix) {
+		leading = leading[:len(prefix)]
+	}
+
+	return fmt.Errorf("last HTTP(s) response body should start with bytes %X, but starts with: %X (body length: %d)", prefix, leading, len(body))
+}
+
+// TheResponseBodyLineCountShouldBe checks whether last response body has given number of lines.
+// Lines are separated by new line character, trailing new line doesn't start new line and empty body has 0 lines.
+func (s *Scenario) TheResponseBodyLineCountShouldBe(n int) error {
+	count, err := s.lastResponseBodyLineCount()
+	if err != nil {
+		return err
+	}
+
+	if count != n {
+		return fmt.Errorf("last response body should have %d lines, but has %d", n, count)
+	}
+
+	return nil
+}
+
+// TheResponseBodyLineCountShouldBeLessOrMoreThan checks whether last response body has less or more lines than n.
+// Lines are counted the same way as in TheResponseBodyLineCountShouldBe.
+func (s *Scenario) TheResponseBodyLineCountShouldBeLessOrMoreThan(comparison string, n int) error {
+	count, err := s.lastResponseBodyLineCount()
+	if err != nil {
+		return err
+	}
+
+	switch comparison {
+	case "less":
+		if count >= n {
+			return fmt.Errorf("last response body should have less than %d lines, but has %d", n, count)
+		}
+	case "more":
+		if count <= n {
+			return fmt.Errorf("last response body should have more than %d lines, but has %d", n, count)
+		}
+	default:
+		return fmt.Errorf("unknown comparison '%s', available: less, more", comparison)
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldMatchTemplate checks whether whole last response body matches provided template.
+// Template consists of literal text, which must match exactly, and placeholders in form {{.FIELD}},
+// which match any text, including new lines. Placeholders are lazy, so each of them matches the shortest text
+// allowing rest of template to match. Placeholders are not resolved against scenario cache.
+func (s *Scenario) TheResponseBodyShouldMatchTemplate(template *godog.DocString) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	literals := templatePlaceholderRegExp.Split(template.Content, -1)
+	if templateRegExp(literals, true).Match(body) {
+		return nil
+	}
+
+	// find first literal text, which could not be matched
+	for i := range literals {
+		if !templateRegExp(literals[:i+1], false).Match(body) {
+			return fmt.Errorf("last response body does not match template, could not match literal text %q, body starts with: %s", literals[i], snippet(body, 0))
+		}
+	}
+
+	return fmt.Errorf("last response body does not match template, unexpected text at the end of body: %s", snippet(body, len(body)))
+}
+
+// TheResponseBodyShouldOrShouldNotMatchRegExp checks whether whole last response body matches or doesn't match
+// provided regExp. RegExp is multiline-aware, so ^ and $ match beginning and end of each line.
+// When body does not match, error shows fragment of body where the longest matching beginning of regExp ends.
+func (s *Scenario) TheResponseBodyShouldOrShouldNotMatchRegExp(not, regExpTemplate string) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	regExpStr, err := s.APIContext.TemplateEngine.Replace(regExpTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'regExp' template, err: %w", err)
+	}
+
+	regExp, err := regexp.Compile("(?m)" + regExpStr)
+	if err != nil {
+		return fmt.Errorf("could not compile regExp '%s', err: %w", regExpStr, err)
+	}
+
+	loc := regExp.FindIndex(body)
+	if len(not) > 0 {
+		if loc != nil {
+			return fmt.Errorf("last response body should not match regExp '%s', but it matches: %s", regExpStr, snippet(body, loc[0]))
+		}
+
+		return nil
+	}
+
+	if loc == nil {
+		return fmt.Errorf("last response body should match regExp '%s', but it doesn't, regExp stops matching near: %s", regExpStr, snippet(body, partialMatchEnd(regExp, body)))
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldMatchRegExpNTimes checks whether last response body contains exactly n non-overlapping
+// matches of regExp. Multiline mode is on, so ^ and $ match at line boundaries.
+func (s *Scenario) TheResponseBodyShouldMatchRegExpNTimes(regExpTemplate string, n int) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	regExpStr, err := s.APIContext.TemplateEngine.Replace(regExpTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'regExp' template, err: %w", err)
+	}
+
+	regExp, err := regexp.Compile("(?m)" + regExpStr)
+	if err != nil {
+		return fmt.Errorf("could not compile regExp '%s', err: %w", regExpStr, err)
+	}
+
+	if count := len(regExp.FindAllIndex(body, -1)); count != n {
+		return fmt.Errorf("last response body should match regExp '%s' %d times, got: %d", regExpStr, n, count)
+	}
+
+	return nil
+}
+
+// TheResponseJSONKeysShouldBe checks whether all keys of objects in last response JSON body,
+// including nested ones, are written in provided style: camelCase, snake_case or PascalCase.
+func (s *Scenario) TheResponseJSONKeysShouldBe(style string) error {
+	var keyRegExp *regexp.Regexp
+	switch style {
+	case "camelCase":
+		keyRegExp = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	case "snake_case":
+		keyRegExp = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	case "PascalCase":
+		keyRegExp = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	default:
+		return fmt.Errorf("unknown keys style '%s', available: camelCase, snake_case, PascalCase", style)
+	}
+
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	var data any
+	if err = json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("last response body is not valid JSON, err: %w", err)
+	}
+
+	var violations []string
+	walkJSONKeys("$", data, func(keyPath, key string) {
+		if !keyRegExp.MatchString(key) {
+			violations = append(violations, keyPath)
+		}
+	})
+
+	if len(violations) > 0 {
+		return fmt.Errorf("all JSON response keys should be %s, but following are not: %s", style, strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+// TheResponseBodyShouldOrShouldNotHaveFormat checks whether last response body has given data format.
+// Available data formats are listed in format package.
+func (s *Scenario) TheResponseBodyShouldOrShouldNotHaveFormat(not, dataFormat string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertResponseFormatIsNot(df.DataFormat(strings.ToLower(dataFormat)))
+	}
+
+	return s.APIContext.AssertResponseFormatIs(df.DataFormat(strings.ToLower(dataFormat)))
+}
+
+/*
+IValidateLastResponseBodyWithSchema validates last response body against JSON schema under provided reference.
+reference may be:
+  - full OS path to JSON schema
+  - relative path from JSON schema's dir which was passed in main_test to initialize *Scenario struct instance,
+  - URL
+*/
+func (s *Scenario) IValidateLastResponseBodyWithSchema(referenceTemplate string) error {
+	return s.APIContext.AssertResponseMatchesSchemaByReference(referenceTemplate)
+}
+
+/*
+TheResponseShouldBeValidAgainstAnyOf validates last response body against JSON schemas under provided references
+separated by comma ",". Validation passes if last response body is valid against at least one of them.
+Each reference may be:
+  - full OS path to JSON schema
+  - relative path from JSON schema's dir which was passed in main_test to initialize *Scenario struct instance,
+  - URL
+*/
+func (s *Scenario) TheResponseShouldBeValidAgainstAnyOf(referencesTemplate string) error {
+	references, err := s.APIContext.TemplateEngine.Replace(referencesTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'references' template, err: %w", err)
+	}
+
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	var validationErrs []string
+	for _, reference := range strings.Split(references, ",") {
+		reference = strings.TrimSpace(reference)
+		if err = s.APIContext.SchemaValidators.ReferenceValidator.Validate(string(body), reference); err == nil {
+			return nil
+		}
+
+		validationErrs = append(validationErrs, fmt.Sprintf("%s: %s", reference, err.Error()))
+	}
+
+	return fmt.Errorf("last response body is not valid according to any of schemas:\n%s", strings.Join(validationErrs, "\n"))
+}
+
+// TheResponseShouldValidateAgainstItsDeclaredSchema validates last response body, which should be JSON,
+// against JSON schema which URL is value of last response body node. Relative URL is resolved against URL of last request.
+func (s *Scenario) TheResponseShouldValidateAgainstItsDeclaredSchema(schemaURLExprTemplate string) error {
+	node, err := s.lastResponseNode(df.JSON, schemaURLExprTemplate)
+	if err != nil {
+		return err
+	}
+
+	rawURL, ok := node.(string)
+	if !ok {
+		return fmt.Errorf("node '%s' should be string with schema URL, got: %T", schemaURLExprTemplate, node)
+	}
+
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	schemaURL, err := resolveResponseURL(lastResp, rawURL)
+	if err != nil {
+		return fmt.Errorf("node '%s' value '%s' is not valid URL, err: %w", schemaURLExprTemplate, rawURL, err)
+	}
+
+	schemaDoc, err := gojsonschema.NewReferenceLoader(schemaURL).LoadJSON()
+	if err != nil {
+		return fmt.Errorf("could not fetch schema '%s', err: %w", schemaURL, err)
+	}
+
+	schemaLoader := gojsonschema.NewSchemaLoader()
+	if err = schemaLoader.AddSchema(schemaURL, gojsonschema.NewGoLoader(schemaDoc)); err != nil {
+		return fmt.Errorf("could not compile schema '%s', err: %w", schemaURL, err)
+	}
+
+	schema, err := schemaLoader.Compile(gojsonschema.NewReferenceLoader(schemaURL))
+	if err != nil {
+		return fmt.Errorf("could not compile schema '%s', err: %w", schemaURL, err)
+	}
+
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(body))
+	if err != nil {
+		return fmt.Errorf("could not validate last response body, err: %w", err)
+	}
+
+	if err = schemaValidationError(result); err != nil {
+		return fmt.Errorf("last response body is not valid according to its declared schema '%s', err: %w", schemaURL, err)
+	}
+
+	return nil
+}
+
+/*
+TheResponseShouldNotBeValidAccordingToSchema checks whether validation of last response body against JSON schema
+under provided reference fails. Validation error is saved in cache under LastSchemaValidationError key,
+so its violations may be checked using TheResponseShouldFailSchemaValidationWithError method.
+reference may be:
+  - full OS path to JSON schema
+  - relative path from JSON schema's dir which was passed in main_test to initialize *Scenario struct instance,
+  - URL
+*/
+func (s *Scenario) TheResponseShouldNotBeValidAccordingToSchema(referenceTemplate string) error {
+	err := s.IValidateLastResponseBodyWithSchema(referenceTemplate)
+	if err == nil {
+		return fmt.Errorf("last response body should not be valid according to schema '%s', but it is", referenceTemplate)
+	}
+
+	s.APIContext.Cache.Save(LastSchemaValidationError, err.Error())
+
+	return nil
+}
+
+// TheResponseShouldFailSchemaValidationWithError checks whether error of last failed validation of response body
+// against JSON schema, made using TheResponseShouldNotBeValidAccordingToSchema method, contains given substring,
+// for example JSON path or keyword of expected violation.
+func (s *Scenario) TheResponseShouldFailSchemaValidationWithError(substringTemplate string) error {
+	substring, err := s.APIContext.TemplateEngine.Replace(substringTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'substring' template, err: %w", err)
+	}
+
+	validationErr, err := s.cachedString(LastSchemaValidationError)
+	if err != nil {
+		return fmt.Errorf("response body validation against schema should fail first, err: %w", err)
+	}
+
+	if !strings.Contains(validationErr, substring) {
+		return fmt.Errorf("schema validation error should contain '%s', got: %s", substring, validationErr)
+	}
+
+	return nil
+}
+
+// IValidateLastResponseBodyWithFollowingSchema validates last response body against JSON schema provided by user.
+func (s *Scenario) IValidateLastResponseBodyWithFollowingSchema(schemaBytes *godog.DocString) error {
+	return s.APIContext.AssertResponseMatchesSchemaByString(schemaBytes.Content)
+}
+
+/*
+TimeBetweenLastHTTPRequestResponseShouldBeLessThanOrEqualTo asserts that last HTTP request-response time
+is <= than expected timeInterval.
+timeInterval should be string acceptable by time.ParseDuration func
+*/
+func (s *Scenario) TimeBetweenLastHTTPRequestResponseShouldBeLessThanOrEqualTo(timeInterval string) error {
+	duration, err := time.ParseDuration(timeInterval)
+	if err != nil {
+		return err
+	}
+
+	return s.APIContext.AssertTimeBetweenRequestAndResponseIs(duration)
+}
+
+/*
+TimeBetweenCachedTimestampsShouldBeLessThan asserts that absolute difference between two timestamps saved in cache
+under keyA and keyB is less than expected timeInterval. Timestamps should be RFC3339 strings, for example saved
+from response nodes, or time.Time values.
+timeInterval should be string acceptable by time.ParseDuration func
+*/
+func (s *Scenario) TimeBetweenCachedTimestampsShouldBeLessThan(keyA, keyB, timeInterval string) error {
+	interval, err := time.ParseDuration(timeInterval)
+	if err != nil {
+		return fmt.Errorf("could not parse time interval '%s', err: %w", timeInterval, err)
+	}
+
+	a, err := s.cachedTime(keyA)
+	if err != nil {
+		return err
+	}
+
+	b, err := s.cachedTime(keyB)
+	if err != nil {
+		return err
+	}
+
+	diff := b.Sub(a)
+	if diff < 0 {
+		diff = -diff
+	}
+
+	if diff >= interval {
+		return fmt.Errorf("time between cached timestamps '%s' (%s) and '%s' (%s) should be less than %s, got: %s",
+			keyA, a.Format(time.RFC3339Nano), keyB, b.Format(time.RFC3339Nano), interval, diff)
+	}
+
+	return nil
+}
+
+/*
+TimeToFirstByteShouldBeLessThan asserts that time between sending last HTTP(s) request
+and receiving first byte of its response is less than expected timeInterval.
+timeInterval should be string acceptable by time.ParseDuration func
+*/
+func (s *Scenario) TimeToFirstByteShouldBeLessThan(timeInterval string) error {
+	return s.timingShouldBeLessThan(func(trace RequestTrace) time.Duration { return trace.TimeToFirstByte }, "time to first byte", timeInterval)
+}
+
+/*
+DNSLookupTimeShouldBeLessThan asserts that DNS lookup time of last HTTP(s) request is less than expected timeInterval.
+timeInterval should be string acceptable by time.ParseDuration func
+*/
+func (s *Scenario) DNSLookupTimeShouldBeLessThan(timeInterval string) error {
+	return s.timingShouldBeLessThan(func(trace RequestTrace) time.Duration { return trace.DNSLookupTime }, "DNS lookup time", timeInterval)
+}
+
+/*
+ConnectTimeShouldBeLessThan asserts that time of establishing connection for last HTTP(s) request
+is less than expected timeInterval.
+timeInterval should be string acceptable by time.ParseDuration func
+*/
+func (s *Scenario) ConnectTimeShouldBeLessThan(timeInterval string) error {
+	return s.timingShouldBeLessThan(func(trace RequestTrace) time.Duration { return trace.ConnectTime }, "connect time", timeInterval)
+}
+
+// LastRequestShouldHaveReusedConnection asserts that last HTTP(s) request was sent over connection reused
+// from connection pool, instead of newly established one. It verifies that server supports keep-alive.
+func (s *Scenario) LastRequestShouldHaveReusedConnection() error {
+	trace, err := s.lastRequestTrace()
+	if err != nil {
+		return fmt.Errorf("could not obtain information about connection of last HTTP(s) request, err: %w", err)
+	}
+
+	if !trace.ConnectionReused {
+		return errors.New("last HTTP(s) request should have reused connection, but new connection was established")
+	}
+
+	return nil
+}
+
+// TheResponseShouldOrShouldNotHaveCookie checks whether last HTTP(s) response has cookie of given name.
+func (s *Scenario) TheResponseShouldOrShouldNotHaveCookie(not, name string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertResponseCookieNotExists(name)
+	}
+
+	return s.APIContext.AssertResponseCookieExists(name)
+}
+
+// TheResponseShouldHaveCookieOfValue checks whether last HTTP(s) response has cookie of given name and value.
+func (s *Scenario) TheResponseShouldHaveCookieOfValue(name, valueTemplate string) error {
+	return s.APIContext.AssertResponseCookieValueIs(name, valueTemplate)
+}
+
+// TheResponseCookieShouldOrShouldNotMatchRegExp checks whether last HTTP(s) response has cookie of given name and value
+// matches/doesn't match provided regExp.
+func (s *Scenario) TheResponseCookieShouldOrShouldNotMatchRegExp(name, not, regExpTemplate string) error {
+	if len(not) > 0 {
+		return s.APIContext.AssertResponseCookieValueNotMatchesRegExp(name, regExpTemplate)
+	}
+
+	return s.APIContext.AssertResponseCookieValueMatchesRegExp(name, regExpTemplate)
+}
+
+// IValidateNodeWithSchemaReference validates last response body node against schema as provided in reference
+func (s *Scenario) IValidateNodeWithSchemaReference(dataFormat, exprTemplate, referenceTemplate string) error {
+	return s.APIContext.AssertNodeMatchesSchemaByReference(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, referenceTemplate)
+}
+
+// IValidateNodeWithSchemaString validates last response body JSON node against schema
+func (s *Scenario) IValidateNodeWithSchemaString(dataFormat, exprTemplate string, schemaTemplate *godog.DocString) error {
+	return s.APIContext.AssertNodeMatchesSchemaByString(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, schemaTemplate.Content)
+}
+
+// TheCachedValuesShouldBeEqual checks whether values saved in cache under keyA and keyB are equal.
+// Comparison is type-aware, so for example int 1 and string "1" are not equal.
+func (s *Scenario) TheCachedValuesShouldBeEqual(keyA, keyB string) error {
+	valueA, err := s.APIContext.Cache.GetSaved(keyA)
+	if err != nil {
+		return fmt.Errorf("could not obtain value saved under key '%s', err: %w", keyA, err)
+	}
+
+	valueB, err := s.APIContext.Cache.GetSaved(keyB)
+	if err != nil {
+		return fmt.Errorf("could not obtain value saved under key '%s', err: %w", keyB, err)
+	}
+
+	if !reflect.DeepEqual(valueA, valueB) {
+		return fmt.Errorf("cached values are not equal, '%s': %#v (%T), '%s': %#v (%T)", keyA, valueA, valueA, keyB, valueB, valueB)
+	}
+
+	return nil
+}
+
+// TheCachedResponseStatusShouldBe checks whether response saved in cache under cacheKey,
+// using ISaveLastResponseAs method, has given status code.
+func (s *Scenario) TheCachedResponseStatusShouldBe(cacheKey string, code int) error {
+	resp, err := s.cachedResponse(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != code {
+		return fmt.Errorf("cached response '%s' status code should be %d, got: %d", cacheKey, code, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ISaveAs saves into cache arbitrary passed value
+func (s *Scenario) ISaveAs(valueTemplate, cacheKey string) error {
+	return s.APIContext.Save(valueTemplate, cacheKey)
+}
+
+// ISaveFollowingAs saves into cache arbitrary passed data. Data may be multiline.
+func (s *Scenario) ISaveFollowingAs(cacheKey string, data *godog.DocString) error {
+	return s.ISaveAs(data.Content, cacheKey)
+}
+
+// ISaveFromTheLastResponseNodeAs saves from last response json node under given cache key.
+func (s *Scenario) ISaveFromTheLastResponseNodeAs(dataFormat, exprTemplate, cacheKey string) error {
+	return s.APIContext.SaveNode(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate, cacheKey)
+}
+
+// ISaveFromTheLastResponseYAMLNodeAs saves from last response YAML node under given cache key.
+// exprTemplate should be valid YAML path expression, for example: $.items[0].name
+func (s *Scenario) ISaveFromTheLastResponseYAMLNodeAs(exprTemplate, cacheKey string) error {
+	return s.APIContext.SaveNode(df.YAML, exprTemplate, cacheKey)
+}
+
+// ISaveFromCachedJSONNodeAs saves node of JSON saved in cache under cacheKey as saveKey.
+// Cached value may be JSON string, JSON bytes or already deserialized data, for example node saved from earlier response.
+func (s *Scenario) ISaveFromCachedJSONNodeAs(cacheKey, exprTemplate, saveKey string) error {
+	cached, err := s.cachedJSON(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("could not serialize value saved under key '%s' to JSON, err: %w", cacheKey, err)
+	}
+
+	expr, err := s.APIContext.TemplateEngine.Replace(exprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	node, err := s.findNode(df.JSON, expr, data)
+	if err != nil {
+		return fmt.Errorf("problem with JSON saved under key '%s', err: %w", cacheKey, err)
+	}
+
+	s.APIContext.Cache.Save(saveKey, node)
+
+	return nil
+}
+
+// ISaveFromTheLastResponseHeaderAs saves from last response header value under given cache key
+func (s *Scenario) ISaveFromTheLastResponseHeaderAs(headerName, cacheKey string) error {
+	return s.APIContext.SaveHeader(headerName, cacheKey)
+}
+
+// ISaveLastResponseBodyAs saves last response body under given cache key.
+func (s *Scenario) ISaveLastResponseBodyAs(cacheKey string) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	s.APIContext.Cache.Save(cacheKey, string(body))
+
+	return nil
+}
+
+// ISaveLastResponseAs saves snapshot of last response, containing its status code, headers and body,
+// under given cache key. Snapshot is saved as SavedResponse.
+func (s *Scenario) ISaveLastResponseAs(cacheKey string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	s.APIContext.Cache.Save(cacheKey, SavedResponse{
+		StatusCode: lastResp.StatusCode,
+		Headers:    lastResp.Header.Clone(),
+		Body:       string(body),
+	})
+
+	return nil
+}
+
+// ISaveLastResponseStatusCodeAs saves last response status code as integer under given cache key.
+func (s *Scenario) ISaveLastResponseStatusCodeAs(cacheKey string) error {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	s.APIContext.Cache.Save(cacheKey, lastResp.StatusCode)
+
+	return nil
+}
+
+// ISaveLinkRelationURLAs saves URL of link of given relation from last HTTP(s) response Link header
+// in cache under given key. Relative URL is resolved against URL of last HTTP(s) request.
+func (s *Scenario) ISaveLinkRelationURLAs(rel, cacheKey string) error {
+	linkURL, err := s.lastResponseLinkRelationURL(rel)
+	if err != nil {
+		return err
+	}
+
+	s.APIContext.Cache.Save(cacheKey, linkURL)
+
+	return nil
+}
+
+// IRemoveCacheKey removes value saved in cache under given key, leaving other values untouched.
+// Removing missing key results in error, so typos in cache keys are not silently ignored.
+// Scenario cache should be able to delete single key, for example *DeletableCache.
+func (s *Scenario) IRemoveCacheKey(cacheKey string) error {
+	deletable, ok := s.APIContext.Cache.(interface{ Delete(key string) error })
+	if !ok {
+		return fmt.Errorf("could not remove cache key '%s', because cache %T can not delete single key", cacheKey, s.APIContext.Cache)
+	}
+
+	if err := deletable.Delete(cacheKey); err != nil {
+		return fmt.Errorf("could not remove cache key '%s', err: %w", cacheKey, err)
+	}
+
+	return nil
+}
+
+// ISaveNodeSliceLengthAs saves number of elements of last response body node, which should be slice,
+// in cache under given key.
+func (s *Scenario) ISaveNodeSliceLengthAs(dataFormat, exprTemplate, cacheKey string) error {
+	length, err := s.lastResponseNodeSliceLength(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	s.APIContext.Cache.Save(cacheKey, length)
+
+	return nil
+}
+
+// ISaveNodeBoolAs saves value of last response body node, which should be boolean, in cache under given key.
+func (s *Scenario) ISaveNodeBoolAs(dataFormat, exprTemplate, cacheKey string) error {
+	value, err := s.lastResponseNodeBool(df.DataFormat(strings.ToLower(dataFormat)), exprTemplate)
+	if err != nil {
+		return err
+	}
+
+	s.APIContext.Cache.Save(cacheKey, value)
+
+	return nil
+}
+
+// ISaveHTMLElementTextAs saves in cache under given key text content of first element of last response body,
+// which should be HTML, matching CSS selector. Leading and trailing white spaces are trimmed.
+func (s *Scenario) ISaveHTMLElementTextAs(selectorTemplate, cacheKey string) error {
+	selector, elements, err := s.lastResponseHTMLElements(selectorTemplate)
+	if err != nil {
+		return err
+	}
+
+	if len(elements) == 0 {
+		return fmt.Errorf("last response HTML does not have any element matching '%s'", selector)
+	}
+
+	s.APIContext.Cache.Save(cacheKey, strings.TrimSpace(htmlText(elements[0])))
+
+	return nil
+}
+
+// IURLEncodeCachedValueAndSaveAs URL encodes string value saved in cache under srcKey
+// and saves result in cache under dstKey.
+func (s *Scenario) IURLEncodeCachedValueAndSaveAs(srcKey, dstKey string) error {
+	value, err := s.cachedString(srcKey)
+	if err != nil {
+		return err
+	}
+
+	s.APIContext.Cache.Save(dstKey, url.QueryEscape(value))
+
+	return nil
+}
+
+// IURLDecodeCachedValueAndSaveAs URL decodes string value saved in cache under srcKey
+// and saves result in cache under dstKey.
+func (s *Scenario) IURLDecodeCachedValueAndSaveAs(srcKey, dstKey string) error {
+	value, err := s.cachedString(srcKey)
+	if err != nil {
+		return err
+	}
+
+	decoded, err := url.QueryUnescape(value)
+	if err != nil {
+		return fmt.Errorf("could not URL decode value '%s' saved under key '%s', err: %w", value, srcKey, err)
+	}
+
+	s.APIContext.Cache.Save(dstKey, decoded)
+
+	return nil
+}
+
+// IPrintLastResponseBody prints response body from last scenario request
+func (s *Scenario) IPrintLastResponseBody() error {
+	return s.APIContext.DebugPrintResponseBody()
+}
+
+// IPrintLastResponseNode prints pretty formatted node of last response body. Data format of last response body
+// is detected automatically and may be one of: JSON, YAML, HTML or XML.
+func (s *Scenario) IPrintLastResponseNode(exprTemplate string) error {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	var format df.DataFormat
+	switch {
+	case df.IsJSON(body):
+		format = df.JSON
+	case df.IsYAML(body):
+		format = df.YAML
+	case df.IsHTML(body):
+		format = df.HTML
+	case df.IsXML(body):
+		format = df.XML
+	default:
+		return fmt.Errorf("could not detect data format of last response body, it starts with: %s", snippet(body, 0))
+	}
+
+	node, err := s.lastResponseNode(format, exprTemplate)
+	if err != nil {
+		return fmt.Errorf("node '%s' is missing in last response body (%s), err: %w", exprTemplate, format, err)
+	}
+
+	if str, ok := node.(string); ok {
+		s.APIContext.Debugger.Print(fmt.Sprintf("node '%s':\n%s", exprTemplate, str))
+
+		return nil
+	}
+
+	normalized, err := normalizeJSON(node)
+	if err != nil {
+		return fmt.Errorf("problem with node '%s', err: %w", exprTemplate, err)
+	}
+
+	pretty, err := json.MarshalIndent(normalized, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not format node '%s', err: %w", exprTemplate, err)
+	}
+
+	s.APIContext.Debugger.Print(fmt.Sprintf("node '%s':\n%s", exprTemplate, pretty))
+
+	return nil
+}
+
+// IPrintCacheData prints all current scenario cache data.
+func (s *Scenario) IPrintCacheData() error {
+	fmt.Printf("%#v", s.APIContext.Cache.All())
+
+	return nil
+}
+
+// IPrintDiffBetweenCachedJSON prints differences between JSON values saved in cache under keyA and keyB.
+// It is debugging aid, so differences don't cause step failure.
+func (s *Scenario) IPrintDiffBetweenCachedJSON(keyA, keyB string) error {
+	valueA, err := s.cachedJSON(keyA)
+	if err != nil {
+		return err
+	}
+
+	valueB, err := s.cachedJSON(keyB)
+	if err != nil {
+		return err
+	}
+
+	diffs := jsonDiff("$", valueA, valueB)
+	if len(diffs) == 0 {
+		s.APIContext.Debugger.Print(fmt.Sprintf("no differences between '%s' and '%s'", keyA, keyB))
+
+		return nil
+	}
+
+	s.APIContext.Debugger.Print(fmt.Sprintf("differences between '%s' (expected) and '%s' (actual):\n%s", keyA, keyB, strings.Join(diffs, "\n")))
+
+	return nil
+}
+
+/*
+IWait waits for provided time interval amount of time
+timeInterval should be string valid for time.ParseDuration func,
+for example: 3s, 1h, 30ms
+*/
+func (s *Scenario) IWait(timeInterval string) error {
+	duration, err := time.ParseDuration(timeInterval)
+	if err != nil {
+		return err
+	}
+
+	return s.APIContext.Wait(duration)
+}
+
+/*
+IWaitUntil waits until provided point in time.
+timeTemplate may contain template values and should be valid time according to provided layout,
+layout should be valid layout for golang standard library time.Parse func, for example: 2006-01-02T15:04:05Z07:00
+If provided point in time has already passed, method does not wait.
+*/
+func (s *Scenario) IWaitUntil(timeTemplate, layout string) error {
+	timeStr, err := s.APIContext.TemplateEngine.Replace(timeTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with 'time' template, err: %w", err)
+	}
+
+	until, err := time.Parse(layout, timeStr)
+	if err != nil {
+		return fmt.Errorf("could not parse time '%s' using layout '%s', err: %w", timeStr, layout, err)
+	}
+
+	duration := time.Until(until)
+	if duration <= 0 {
+		s.APIContext.Debugger.Print(fmt.Sprintf("time %s has already passed, skipping waiting", until.Format(time.RFC3339)))
+
+		return nil
+	}
+
+	return s.APIContext.Wait(duration)
+}
+
+// IStartDebugMode starts debugging mode
+func (s *Scenario) IStartDebugMode() error {
+	return s.APIContext.DebugStart()
+}
+
+// IStopDebugMode stops debugging mode
+func (s *Scenario) IStopDebugMode() error {
+	return s.APIContext.DebugStop()
+}
+
+// IStopScenarioExecution stops scenario execution
+func (s *Scenario) IStopScenarioExecution() error {
+	return errors.New("scenario stopped")
+}
+
+// lastResponseNode returns node obtained from last HTTP(s) response body using exprTemplate.
+// exprTemplate should be valid according to injected PathFinder for provided dataFormat.
+func (s *Scenario) lastResponseNode(dataFormat df.DataFormat, exprTemplate string) (any, error) {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	expr, err := s.APIContext.TemplateEngine.Replace(exprTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return nil, fmt.Errorf("template engine has problem with 'expression' template, err: %w", err)
+	}
+
+	return s.findNode(dataFormat, expr, body)
+}
+
+// lastResponseNodeSemVer returns parsed semantic version from last response body node, which should be string.
+func (s *Scenario) lastResponseNodeSemVer(dataFormat df.DataFormat, exprTemplate string) (semVer, error) {
+	node, err := s.lastResponseNode(dataFormat, exprTemplate)
+	if err != nil {
+		return semVer{}, err
+	}
+
+	str, ok := node.(string)
+	if !ok {
+		return semVer{}, fmt.Errorf("node '%s' should be string with semantic version, got: %#v (%T)", exprTemplate, node, node)
+	}
+
+	version, err := parseSemVer(strings.TrimSpace(str))
+	if err != nil {
+		return semVer{}, fmt.Errorf("node '%s' should be valid semantic version, err: %w", exprTemplate, err)
+	}
+
+	return version, nil
+}
+
+// lastResponseHTMLElements returns resolved CSS selector and elements of last response body, which should be HTML,
+// matching it in document order.
+func (s *Scenario) lastResponseHTMLElements(selectorTemplate string) (string, []*html.Node, error) {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	selector, err := s.APIContext.TemplateEngine.Replace(selectorTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return "", nil, fmt.Errorf("template engine has problem with 'selector' template, err: %w", err)
+	}
+
+	parsed, err := cascadia.ParseGroup(selector)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not parse CSS selector '%s', err: %w", selector, err)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("could not parse last response body as HTML, err: %w", err)
+	}
+
+	return selector, cascadia.QueryAll(doc, parsed), nil
+}
+
+// lastResponseNodeBool returns value of last response body node, which should be boolean.
+func (s *Scenario) lastResponseNodeBool(dataFormat df.DataFormat, exprTemplate string) (bool, error) {
+	node, err := s.lastResponseNode(dataFormat, exprTemplate)
+	if err != nil {
+		return false, err
+	}
+
+	value, ok := node.(bool)
+	if !ok {
+		return false, fmt.Errorf("node '%s' should be boolean, got: %#v (%T)", exprTemplate, node, node)
+	}
+
+	return value, nil
+}
+
+// lastResponseLinkRelationURL returns URL of link of given relation from last HTTP(s) response Link header.
+// Relative URL is resolved against URL of last HTTP(s) request.
+func (s *Scenario) lastResponseLinkRelationURL(rel string) (string, error) {
+	lastResp, err := s.APIContext.GetLastResponse()
+	if err != nil {
+		return "", fmt.Errorf("could not obtain last HTTP(s) response, err: %w", err)
+	}
+
+	rels, urls := linkRelations(lastResp.Header.Values("Link"))
+	linkURL, ok := urls[strings.ToLower(rel)]
+	if !ok {
+		return "", fmt.Errorf("last HTTP(s) response Link header should have relation '%s', available relations: %v", rel, rels)
+	}
+
+	resolved, err := resolveResponseURL(lastResp, linkURL)
+	if err != nil {
+		return "", fmt.Errorf("could not parse URL '%s' of relation '%s', err: %w", linkURL, rel, err)
+	}
+
+	return resolved, nil
+}
+
+// resolveResponseURL returns rawURL resolved against URL of request, that resulted in provided response.
+func resolveResponseURL(resp *http.Response, rawURL string) (string, error) {
+	ref, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Request == nil {
+		return ref.String(), nil
+	}
+
+	return resp.Request.URL.ResolveReference(ref).String(), nil
+}
+
+// lastResponseBodyLineCount returns number of lines of last response body.
+func (s *Scenario) lastResponseBodyLineCount() (int, error) {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return 0, fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	if len(body) == 0 {
+		return 0, nil
+	}
+
+	count := bytes.Count(body, []byte("\n"))
+	if body[len(body)-1] != '\n' {
+		count++
+	}
+
+	return count, nil
+}
+
+// lastRequest returns last sent HTTP(s) request.
+func (s *Scenario) lastRequest() (*http.Request, error) {
+	trace, err := s.lastRequestTrace()
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain last HTTP(s) request, err: %w", err)
+	}
+
+	return trace.LastRequest, nil
+}
+
+// requestTrace returns details about HTTP(s) requests sent during scenario, collected by *TracingRequestDoer.
+func (s *Scenario) requestTrace() (RequestTrace, error) {
+	tracing, ok := s.APIContext.RequestDoer.(*TracingRequestDoer)
+	if !ok {
+		return RequestTrace{}, fmt.Errorf("details about sent HTTP(s) requests are collected only by request doer %T, got: %T", tracing, s.APIContext.RequestDoer)
+	}
+
+	return tracing.Trace(), nil
+}
+
+// lastRequestTrace returns details about HTTP(s) requests sent during scenario, if at least one request was sent.
+func (s *Scenario) lastRequestTrace() (RequestTrace, error) {
+	trace, err := s.requestTrace()
+	if err != nil {
+		return RequestTrace{}, err
+	}
+
+	if trace.RequestsSent == 0 {
+		return RequestTrace{}, errors.New("no HTTP(s) request was sent yet")
+	}
+
+	return trace, nil
+}
+
+// lastResponseJSONRoot returns deserialized last response body, which should be JSON.
+func (s *Scenario) lastResponseJSONRoot() (any, error) {
+	body, err := s.APIContext.GetLastResponseBody()
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain last HTTP(s) response body, err: %w", err)
+	}
+
+	var root any
+	if err = json.Unmarshal(body, &root); err != nil {
+		return nil, fmt.Errorf("last response body is not valid JSON, err: %w", err)
+	}
+
+	return root, nil
+}
+
+// lastResponseNodeSliceLength returns number of elements of last response body node, which should be slice.
+func (s *Scenario) lastResponseNodeSliceLength(dataFormat df.DataFormat, exprTemplate string) (int, error) {
+	node, err := s.lastResponseNode(dataFormat, exprTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := reflect.ValueOf(node); v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Len(), nil
+	default:
+		return 0, fmt.Errorf("node '%s' should be slice, got: %T", exprTemplate, node)
+	}
+}
+
+// templatePlaceholderRegExp matches placeholders used by TheResponseBodyShouldMatchTemplate.
+var templatePlaceholderRegExp = regexp.MustCompile(`{{\s*\.\w+\s*}}`)
+
+// templateRegExp returns regExp matching literal texts separated by placeholders at the beginning of data.
+// If whole is true, regExp matches only if literal texts span the whole data.
+func templateRegExp(literals []string, whole bool) *regexp.Regexp {
+	quoted := make([]string, len(literals))
+	for i, literal := range literals {
+		quoted[i] = regexp.QuoteMeta(literal)
+	}
+
+	expr := `(?s)\A` + strings.Join(quoted, `.*?`)
+	if whole {
+		expr += `\z`
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+// findNode returns node obtained from data using expr and PathFinder of provided dataFormat.
+func (s *Scenario) findNode(dataFormat df.DataFormat, expr string, data []byte) (any, error) {
+	if len(data) == 0 {
+		return nil, errors.New("provided empty data")
+	}
+
+	var pathFinder pathfinder.PathFinder
+	switch dataFormat {
+	case df.JSON:
+		pathFinder = s.APIContext.PathFinders.JSON
+	case df.YAML:
+		pathFinder = s.APIContext.PathFinders.YAML
+	case df.XML:
+		pathFinder = s.APIContext.PathFinders.XML
+	case df.HTML:
+		pathFinder = s.APIContext.PathFinders.HTML
+	default:
+		return nil, fmt.Errorf("provided unknown format: %s, format should be one of : %s, %s, %s, %s",
+			dataFormat, df.JSON, df.YAML, df.XML, df.HTML)
+	}
+
+	node, err := pathFinder.Find(expr, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not find node using provided expression: '%s', err: %w", expr, err)
+	}
+
+	return node, nil
+}
+
+// isNodeNotFound checks whether err returned by findNode means, that expression is valid, but node does not exist.
+// Other errors, for example invalid expression or malformed data, are not treated as not found.
+// gdutils path finders do not export errors, so their messages are matched.
+func isNodeNotFound(err error) bool {
+	if yaml.IsNotFoundNodeError(err) || errors.Is(err, jsonpath.ErrGetFromNullObj) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, notFound := range []string{"not found in object", "index out of range", "in given JSON bytes", "in given XML bytes", "in given HTML bytes"} {
+		if strings.Contains(msg, notFound) {
+			return true
+		}
+	}
+
+	// gjson and antchfx/jsonquery, the latter adds ", err:" when expression is invalid
+	_, gdutilsMsg, found := strings.Cut(msg, "could not find node, using expression ")
+
+	return found && !strings.Contains(gdutilsMsg, ", err:")
+}
+
+// preparedRequestBody returns body of previously prepared request.
+// Internally method restores request body, so it may be read again.
+func (s *Scenario) preparedRequestBody(cacheKey string) ([]byte, error) {
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	if req.Body == nil {
+		return []byte{}, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read prepared request '%s' body, err: %w", cacheKey, err)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	s.APIContext.Cache.Save(cacheKey, req)
+
+	return body, nil
+}
+
+// sendAndSaveLastResponse sends req using scenario RequestDoer and reads whole response body.
+// Response is saved in cache as last HTTP(s) response together with timestamps of sending request
+// and receiving response, the same way as APIContext.RequestSend does.
+func (s *Scenario) sendAndSaveLastResponse(req *http.Request) (*http.Response, []byte, error) {
+	return s.sendAndSaveLastResponseReadingWith(req, func(resp *http.Response) ([]byte, error) {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("could not read response body, err: %w", err)
+		}
+
+		return body, nil
+	})
+}
+
+// sendAndSaveLastResponseReadingWith works like sendAndSaveLastResponse, but response body is read by provided read func.
+// Response is not saved as last HTTP(s) response if read returns error.
+func (s *Scenario) sendAndSaveLastResponseReadingWith(req *http.Request, read func(resp *http.Response) ([]byte, error)) (*http.Response, []byte, error) {
+	s.APIContext.Cache.Save(httpcache.LastHTTPRequestTimestamp, time.Now())
+
+	resp, err := s.APIContext.RequestDoer.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request %s %s, reason: %w", req.Method, req.URL.String(), err)
+	}
+
+	body, err := read(resp)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	s.APIContext.Cache.Save(httpcache.LastHTTPResponseTimestamp, time.Now())
+	s.APIContext.Cache.Save(httpcache.LastHTTPResponseCacheKey, resp)
+
+	return resp, body, nil
+}
+
+// partialMatchEnd returns offset in data where the longest matching beginning of regExp ends,
+// or 0 when not even beginning of regExp matches data.
+func partialMatchEnd(regExp *regexp.Regexp, data []byte) int {
+	parsed, err := syntax.Parse(regExp.String(), syntax.Perl)
+	if err != nil {
+		return 0
+	}
+
+	parts := []*syntax.Regexp{parsed}
+	if parsed.Op == syntax.OpConcat {
+		parts = parsed.Sub
+	}
+
+	// literals are split into single characters, so mismatch is found with precision of one character
+	var subs []*syntax.Regexp
+	for _, part := range parts {
+		if part.Op != syntax.OpLiteral {
+			subs = append(subs, part)
+			continue
+		}
+
+		for _, r := range part.Rune {
+			subs = append(subs, &syntax.Regexp{Op: syntax.OpLiteral, Flags: part.Flags, Rune: []rune{r}})
+		}
+	}
+
+	for i := len(subs) - 1; i > 0; i-- {
+		prefix, err := regexp.Compile((&syntax.Regexp{Op: syntax.OpConcat, Sub: subs[:i]}).String())
+		if err != nil {
+			continue
+		}
+
+		if loc := prefix.FindIndex(data); loc != nil {
+			return loc[1]
+		}
+	}
+
+	return 0
+}
+
+// snippet returns fragment of data surrounding provided offset.
+func snippet(data []byte, offset int) string {
+	const radius = 20
+
+	from, to := offset-radius, offset+radius
+	if from < 0 {
+		from = 0
+	}
+
+	if to > len(data) {
+		to = len(data)
+	}
+
+	if from > to {
+		from = to
+	}
+
+	return fmt.Sprintf("'%s'", data[from:to])
+}
+
+// cachedString returns string value saved in cache under cacheKey.
+func (s *Scenario) cachedString(cacheKey string) (string, error) {
+	value, err := s.APIContext.Cache.GetSaved(cacheKey)
+	if err != nil {
+		return "", fmt.Errorf("could not obtain value saved under key '%s', err: %w", cacheKey, err)
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("value saved under key '%s' should be string, got: %T", cacheKey, value)
+	}
+
+	return str, nil
+}
+
+// timingShouldBeLessThan asserts that timing of last HTTP(s) request saved in cache under cacheKey
+// is less than expected timeInterval.
+func (s *Scenario) timingShouldBeLessThan(timingOf func(trace RequestTrace) time.Duration, name, timeInterval string) error {
+	duration, err := time.ParseDuration(timeInterval)
+	if err != nil {
+		return err
+	}
+
+	trace, err := s.lastRequestTrace()
+	if err != nil {
+		return fmt.Errorf("problem during obtaining last HTTP(s) %s, err: %w", name, err)
+	}
+
+	timing := timingOf(trace)
+
+	if timing >= duration {
+		return fmt.Errorf("%s should be less than %+v, but it took %+v", name, duration, timing)
+	}
+
+	return nil
+}
+
+// cachedTime returns time saved in cache under cacheKey. Cached value should be time.Time or RFC3339 string.
+func (s *Scenario) cachedTime(cacheKey string) (time.Time, error) {
+	value, err := s.APIContext.Cache.GetSaved(cacheKey)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not obtain value saved under key '%s', err: %w", cacheKey, err)
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("value saved under key '%s' should be RFC3339 timestamp, got: '%s', err: %w", cacheKey, v, err)
+		}
+
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("value saved under key '%s' should be time.Time or RFC3339 string, got: %T", cacheKey, value)
+	}
+}
+
+// cachedJSON returns deserialized JSON saved in cache under cacheKey.
+// Cached value may be JSON string, JSON bytes or already deserialized data.
+func (s *Scenario) cachedJSON(cacheKey string) (any, error) {
+	value, err := s.APIContext.Cache.GetSaved(cacheKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not obtain value saved under key '%s', err: %w", cacheKey, err)
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return normalizeJSON(v)
+	}
+
+	var deserialized any
+	if err = json.Unmarshal(data, &deserialized); err != nil {
+		return nil, fmt.Errorf("value saved under key '%s' is not valid JSON, err: %w", cacheKey, err)
+	}
+
+	return deserialized, nil
+}
+
+// nodeNumber returns numeric node as float64 and whether node is number at all.
+func nodeNumber(node any) (float64, bool) {
+	switch v := reflect.ValueOf(node); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// schemaEnum returns values listed in enum of JSON schema, which is available under schemaRef and schemaPointer.
+func (s *Scenario) schemaEnum(schemaRef, schemaPointer string) ([]any, error) {
+	source, err := schemaSource(s.JSONSchemaDir, schemaRef)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := gojsonschema.NewReferenceLoader(source).LoadJSON()
+	if err != nil {
+		return nil, fmt.Errorf("could not load schema '%s', err: %w", schemaRef, err)
+	}
+
+	pointer, err := gojsonpointer.NewJsonPointer(strings.TrimPrefix(schemaPointer, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON pointer '%s', err: %w", schemaPointer, err)
+	}
+
+	subSchema, _, err := pointer.Get(schema)
+	if err != nil {
+		return nil, fmt.Errorf("could not find '%s' in schema '%s', err: %w", schemaPointer, schemaRef, err)
+	}
+
+	if m, ok := subSchema.(map[string]any); ok {
+		subSchema = m["enum"]
+	}
+
+	normalized, err := normalizeJSON(subSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	enum, ok := normalized.([]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' of schema '%s' does not have enum", schemaPointer, schemaRef)
+	}
+
+	return enum, nil
+}
+
+// charsetByName returns charset of given name, which should be one of CharsetNames. Name is case-insensitive.
+func charsetByName(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "ascii":
+		return ch.ASCII, nil
+	case "unicode":
+		return ch.Unicode, nil
+	case "polish":
+		return ch.Polish, nil
+	case "english":
+		return ch.English, nil
+	case "russian":
+		return ch.Russian, nil
+	case "japanese":
+		return ch.Japanese, nil
+	case "emoji":
+		return ch.Emoji, nil
+	default:
+		return "", fmt.Errorf("unknown charset '%s', available: %s", name, strings.Join(CharsetNames, ", "))
+	}
+}
+
+// setPreparedRequestHeader sets header for previously prepared request.
+// valueTemplate may contain template values.
+func (s *Scenario) setPreparedRequestHeader(cacheKey, name, valueTemplate string) error {
+	value, err := s.APIContext.TemplateEngine.Replace(valueTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return fmt.Errorf("template engine has problem with '%s' header template, err: %w", name, err)
+	}
+
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	req.Header.Set(name, value)
+	s.APIContext.Cache.Save(cacheKey, req)
+
+	return nil
+}
+
+// setPreparedRequestRawBody sets body of previously prepared request, without processing it by template engine.
+func (s *Scenario) setPreparedRequestRawBody(cacheKey string, body []byte) error {
+	req, err := s.APIContext.GetPreparedRequest(cacheKey)
+	if err != nil {
+		return fmt.Errorf("could not obtain prepared request, err: %w", err)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	req.ContentLength = int64(len(body))
+	s.APIContext.Cache.Save(cacheKey, req)
+
+	return nil
+}
+
+// tableValue returns value of Gherkin table cell processed by template engine. Valid JSON is deserialized,
+// anything else is returned as string.
+func (s *Scenario) tableValue(cellTemplate string) (any, error) {
+	cell, err := s.APIContext.TemplateEngine.Replace(cellTemplate, s.APIContext.Cache.All())
+	if err != nil {
+		return nil, fmt.Errorf("template engine has problem with table cell '%s', err: %w", cellTemplate, err)
+	}
+
+	var value any
+	if err = json.Unmarshal([]byte(cell), &value); err != nil {
+		return cell, nil
+	}
+
+	return value, nil
+}
+
+// walkJSONKeys calls fn for every key of every object in deserialized JSON data, in order of their paths.
+func walkJSONKeys(path string, data any, fn func(keyPath, key string)) {
+	switch v := data.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := path + "." + key
+			fn(keyPath, key)
+			walkJSONKeys(keyPath, v[key], fn)
+		}
+	case []any:
+		for i, element := range v {
+			walkJSONKeys(fmt.Sprintf("%s[%d]", path, i), element, fn)
+		}
+	}
+}
+
+// cachedResponse returns response saved in cache under cacheKey using ISaveLastResponseAs method.
+func (s *Scenario) cachedResponse(cacheKey string) (SavedResponse, error) {
+	value, err := s.APIContext.Cache.GetSaved(cacheKey)
+	if err != nil {
+		return SavedResponse{}, fmt.Errorf("could not obtain value saved under key '%s', err: %w", cacheKey, err)
+	}
+
+	resp, ok := value.(SavedResponse)
+	if !ok {
+		return SavedResponse{}, fmt.Errorf("value saved under key '%s' should be saved response, got: %T", cacheKey, value)
+	}
+
+	return resp, nil
+}
+
+// defaultTransport returns copy of gdutils default transport, that additionally is able to send requests
+// to Unix domain sockets.
+func defaultTransport() *http.Transport {
+	transport, ok := gdutils.DefaultTransport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport = transport.Clone()
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	transport.DialContext = unixSocketDialContext(dial)
+
+	return transport
+}
+
+// setTransport replaces HTTP client used to send requests with one using provided transport.
+// If requests are traced, tracing is preserved.
+func (s *Scenario) setTransport(transport http.RoundTripper) {
+	s.modifyHTTPClient(func(client *http.Client) {
+		client.Transport = &gdutils.CustomTransport{RoundTripper: transport}
+	})
+}
+
+// modifyHTTPClient replaces HTTP client used to send requests with copy of current one, changed by modify func,
+// so other settings of current client are preserved. If requests are traced, tracing is preserved.
+// If request doer is not *http.Client, copy of default client is changed.
+func (s *Scenario) modifyHTTPClient(modify func(client *http.Client)) {
+	doer := s.APIContext.RequestDoer
+	tracing, isTracing := doer.(*TracingRequestDoer)
+	if isTracing {
+		doer = tracing.RequestDoer
+	}
+
+	client := NewHTTPClient()
+	if current, ok := doer.(*http.Client); ok {
+		copied := *current
+		client = &copied
+	}
+
+	modify(client)
+	if isTracing {
+		// details about already sent requests are kept
+		tracing.RequestDoer = client
+
+		return
+	}
+
+	s.APIContext.SetRequestDoer(client)
 }
@@ -0,0 +1,3717 @@
+package defs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cucumber/godog"
+	messages "github.com/cucumber/messages-go/v16"
+	"github.com/gofrs/uuid"
+	"github.com/pawelWritesCode/df"
+	"github.com/pawelWritesCode/gdutils"
+	"github.com/pawelWritesCode/gdutils/pkg/debugger"
+)
+
+// newTestScenario returns *Scenario set up the same way as in main_test.go.
+func newTestScenario(t *testing.T) *Scenario {
+	t.Helper()
+
+	dir := t.TempDir()
+	s := &Scenario{APIContext: gdutils.NewDefaultAPIContext(false, dir), JSONSchemaDir: dir}
+	s.APIContext.SetCache(NewDeletableCache())
+	s.APIContext.SetSchemaReferenceValidator(NewDetailedSchemaReferenceValidator(dir))
+	s.APIContext.SetRequestDoer(NewTracingRequestDoer(NewHTTPClient(), s.APIContext.Cache))
+
+	return s
+}
+
+// prepareRequest prepares request of given method to url and saves it under cacheKey.
+func prepareRequest(t *testing.T, s *Scenario, method, url, cacheKey string) {
+	t.Helper()
+
+	if err := s.IPrepareNewRequestToAndSaveItAs(method, url, cacheKey); err != nil {
+		t.Fatalf("could not prepare request, err: %v", err)
+	}
+}
+
+// newBodyServer returns server responding with JSON body passed in query param "body".
+func newBodyServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// sendGetRequest sends GET request to url, so its response becomes last response.
+func sendGetRequest(t *testing.T, s *Scenario, url string) {
+	t.Helper()
+
+	prepareRequest(t, s, http.MethodGet, url, "GET_REQUEST")
+	if err := s.ISendRequest("GET_REQUEST"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+}
+
+// bodyURL returns URL of srv created by newBodyServer, which responds with body.
+func bodyURL(srv *httptest.Server, body string) string {
+	return srv.URL + "?body=" + url.QueryEscape(body)
+}
+
+// newVersionedServer returns server responding with ETag of current resource version and honoring If-None-Match.
+// Every PUT request changes version of resource.
+func newVersionedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var version int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			atomic.AddInt32(&version, 1)
+		}
+
+		etag := fmt.Sprintf(`"v%d"`, atomic.LoadInt32(&version))
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"version": %s}`, strings.Trim(etag, `"v`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestScenario_ISendPreparedRequestWithRetriesOn5xx(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		maxRetries   int
+		wantAttempts int
+		wantErr      string
+	}{
+		{name: "no failures", failures: 0, maxRetries: 2, wantAttempts: 1},
+		{name: "succeeds on last retry", failures: 2, maxRetries: 2, wantAttempts: 3},
+		{name: "retries run out", failures: 3, maxRetries: 2, wantAttempts: 3, wantErr: "status code 503 after 3 attempts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"ok": true}`))
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodGet, srv.URL, "R")
+
+			err := s.ISendPreparedRequestWithRetriesOn5xx("R", tt.maxRetries, "1ms")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+
+			attempts, err := s.APIContext.Cache.GetSaved(LastHTTPRequestAttempts)
+			if err != nil {
+				t.Fatalf("number of attempts should be saved, err: %v", err)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts: want %d, got %v", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestIsNodeNotFound(t *testing.T) {
+	jsonData := []byte(`{"user": {"name": "John", "address": null, "roles": ["admin"]}}`)
+	yamlData := []byte("user:\n  name: John\n  roles:\n    - admin\n")
+	xmlData := []byte(`<user><name>John</name></user>`)
+
+	tests := []struct {
+		name     string
+		format   df.DataFormat
+		expr     string
+		data     []byte
+		notFound bool
+	}{
+		{name: "gjson missing key", format: df.JSON, expr: "user.age", data: jsonData, notFound: true},
+		{name: "gjson invalid JSON", format: df.JSON, expr: "user.age", data: []byte(`{"user":`), notFound: false},
+		{name: "oliveagle missing key", format: df.JSON, expr: "$.user.age", data: jsonData, notFound: true},
+		{name: "oliveagle index out of range", format: df.JSON, expr: "$.user.roles[3]", data: jsonData, notFound: true},
+		{name: "oliveagle key of null", format: df.JSON, expr: "$.user.address.city", data: jsonData, notFound: true},
+		{name: "oliveagle invalid expression", format: df.JSON, expr: "$.user[?(@.a ~ 1)]", data: jsonData, notFound: false},
+		{name: "jsonquery missing key", format: df.JSON, expr: "/user/age", data: jsonData, notFound: true},
+		{name: "jsonquery invalid expression", format: df.JSON, expr: "/user/[", data: jsonData, notFound: false},
+		{name: "yaml missing key", format: df.YAML, expr: "$.user.age", data: yamlData, notFound: true},
+		{name: "yaml invalid expression", format: df.YAML, expr: "user.age", data: yamlData, notFound: false},
+		{name: "xml missing node", format: df.XML, expr: "//user/age", data: xmlData, notFound: true},
+	}
+
+	s := newTestScenario(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.findNode(tt.format, tt.expr, tt.data)
+			if err == nil {
+				t.Fatalf("expression '%s' should not find node", tt.expr)
+			}
+
+			if got := isNodeNotFound(err); got != tt.notFound {
+				t.Errorf("isNodeNotFound: want %t, got %t, err: %v", tt.notFound, got, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldBeAbsentComparedToCachedResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastBody string
+		expr     string
+		wantErr  string
+	}{
+		{name: "node removed", lastBody: `{"id": 1}`, expr: "name"},
+		{name: "node still present", lastBody: `{"id": 1, "name": "John"}`, expr: "name", wantErr: "should be absent"},
+		{name: "last response is not valid JSON", lastBody: `{"id": `, expr: "name", wantErr: "detected invalid JSON"},
+	}
+
+	srv := newBodyServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, `{"id": 1, "name": "John"}`))
+			if err := s.ISaveLastResponseAs("BEFORE"); err != nil {
+				t.Fatalf("could not save last response, err: %v", err)
+			}
+
+			sendGetRequest(t, s, bodyURL(srv, tt.lastBody))
+			err := s.TheNodeShouldBeAbsentComparedToCachedResponse("JSON", tt.expr, "BEFORE")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldBeJSONNull(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		wantErr     string
+		wantMissing bool
+	}{
+		{name: "null node", expr: "address"},
+		{name: "not null node", expr: "name", wantErr: "it should be null"},
+		{name: "missing node", expr: "age", wantErr: "could not find node", wantMissing: true},
+		{name: "invalid expression", expr: "/user/[", wantErr: "could not find node"},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `{"name": "John", "address": null}`))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.TheNodeShouldBeJSONNull("JSON", tt.expr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+
+			if missing := strings.Contains(err.Error(), "it is missing"); missing != tt.wantMissing {
+				t.Errorf("node reported as missing: want %t, got %t, err: %v", tt.wantMissing, missing, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseHTMLShouldHaveElementsMatching(t *testing.T) {
+	page := `<!doctype html><html><head><title>Shop</title></head><body>
+<ul id="menu"><li class="item active"><a href="https://a" title="a,b">A</a></li><li class="item"><a href="http://b">B</a></li>
+<li class="item"><span><a href="https://c" data-x="q y">C</a></span></li></ul></body></html>`
+
+	tests := []struct {
+		selector string
+		count    int
+		wantErr  bool
+	}{
+		{selector: `[title="a,b"]`, count: 1},
+		{selector: `a[title="a,b"], title`, count: 2},
+		{selector: "li.item.active", count: 1},
+		{selector: "ul#menu > li > a", count: 2},
+		{selector: "li a", count: 3},
+		{selector: "a[href^='https']", count: 2},
+		{selector: "a[data-x~=y]", count: 1},
+		{selector: "li:first-child a", count: 1},
+		{selector: "table", count: 0},
+		{selector: "a[href=", wantErr: true},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, page))
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			err := s.TheResponseHTMLShouldHaveElementsMatching(tt.count, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISaveHTMLElementTextAs(t *testing.T) {
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `<html><body><p class="price"> Price: <b>10</b> USD </p><p class="price">20</p></body></html>`))
+
+	if err := s.ISaveHTMLElementTextAs("p.price", "PRICE"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if price, _ := s.APIContext.Cache.GetSaved("PRICE"); price != "Price: 10 USD" {
+		t.Errorf("saved text: want 'Price: 10 USD', got: %#v", price)
+	}
+
+	if err := s.ISaveHTMLElementTextAs("table", "TABLE"); err == nil {
+		t.Errorf("selector not matching any element should result in error")
+	}
+}
+
+func TestScenario_IDoNotFollowRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		setUp      func(s *Scenario) error
+		wantStatus int
+	}{
+		{name: "redirects are followed by default", setUp: func(s *Scenario) error { return nil }, wantStatus: http.StatusOK},
+		{name: "redirects are not followed", setUp: (*Scenario).IDoNotFollowRedirects, wantStatus: http.StatusFound},
+		{name: "changing transport keeps redirects policy", setUp: func(s *Scenario) error {
+			if err := s.IDoNotFollowRedirects(); err != nil {
+				return err
+			}
+
+			return s.IForceHTTP1()
+		}, wantStatus: http.StatusFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			if err := tt.setUp(s); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, ok := s.APIContext.RequestDoer.(*TracingRequestDoer); !ok {
+				t.Fatalf("requests should still be traced, got request doer: %T", s.APIContext.RequestDoer)
+			}
+
+			sendGetRequest(t, s, srv.URL+"/old")
+			if err := s.TheResponseStatusCodeShouldOrShouldNotBe("", tt.wantStatus); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantStatus != http.StatusFound {
+				return
+			}
+
+			if err := s.TheResponseLocationShouldBe(srv.URL + "/new"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCharsetNames(t *testing.T) {
+	s := newTestScenario(t)
+	for _, name := range CharsetNames {
+		t.Run(name, func(t *testing.T) {
+			charset, err := charsetByName(name)
+			if err != nil {
+				t.Fatalf("charset should be available, err: %v", err)
+			}
+
+			if err = s.IGenerateARandomRunesOfLengthWithCharactersAndSaveItAs(5, 10, name, "WORD"); err != nil {
+				t.Fatalf("could not generate word, err: %v", err)
+			}
+
+			word, _ := s.APIContext.Cache.GetSaved("WORD")
+			for _, r := range word.(string) {
+				if !strings.ContainsRune(charset, r) {
+					t.Errorf("generated word '%s' has character '%c' out of charset", word, r)
+				}
+			}
+
+			if err = s.IGenerateARandomSentenceInTheRangeFromToWordsAndSaveItAs(3, 10)(2, 3, name, "SENTENCE"); err != nil {
+				t.Errorf("could not generate sentence, err: %v", err)
+			}
+		})
+	}
+
+	if _, err := charsetByName("klingon"); err == nil {
+		t.Errorf("unknown charset should result in error")
+	}
+}
+
+func TestScenario_CollectedPaginationItemsShouldHaveNoDuplicates(t *testing.T) {
+	// pages holds ids of items returned on consecutive pages, overlapping pages repeat last item of previous page
+	tests := []struct {
+		name    string
+		pages   [][]int
+		wantErr string
+	}{
+		{name: "correct pagination", pages: [][]int{{1, 2}, {3, 4}, {5}}},
+		{name: "overlapping pages", pages: [][]int{{1, 2}, {2, 3}}, wantErr: "2 is duplicated at items 1 and 2 (of 4 collected)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				items := make([]string, 0, len(tt.pages[page]))
+				for _, id := range tt.pages[page] {
+					items = append(items, fmt.Sprintf(`{"id": %d}`, id))
+				}
+
+				_, _ = fmt.Fprintf(w, `{"page": %d, "items": [%s]}`, page, strings.Join(items, ","))
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			for page := range tt.pages {
+				sendGetRequest(t, s, fmt.Sprintf("%s?page=%d", srv.URL, page))
+				if err := s.ICollectNodeItemsInto("JSON", "items", "ALL_ITEMS"); err != nil {
+					t.Fatalf("could not collect items of page %d, err: %v", page, err)
+				}
+			}
+
+			err := s.CollectedPaginationItemsShouldHaveNoDuplicates("ALL_ITEMS", "id")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldFailSchemaValidationWithError(t *testing.T) {
+	body, err := os.ReadFile("testdata/underage_user_without_first_name.json")
+	if err != nil {
+		t.Fatalf("could not read fixture, err: %v", err)
+	}
+
+	tests := []struct {
+		schema    string
+		substring string
+		wantErr   string
+	}{
+		{schema: "user/response/adult_user.json", substring: "$.firstName [string_gte]"},
+		{schema: "user/response/adult_user.json", substring: "$.age [number_gte]"},
+		{schema: "user/response/adult_user.json", substring: "$.avatar", wantErr: "should contain '$.avatar'"},
+		{schema: "user/response/user.json", substring: "$.age", wantErr: "should not be valid"},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	s.APIContext.SetSchemaReferenceValidator(NewDetailedSchemaReferenceValidator("../assets/test_server/doc/schema"))
+	sendGetRequest(t, s, bodyURL(srv, string(body)))
+	for _, tt := range tests {
+		t.Run(tt.schema+" "+tt.substring, func(t *testing.T) {
+			s.APIContext.Cache.Save(LastSchemaValidationError, "")
+
+			err := s.TheResponseShouldNotBeValidAccordingToSchema(tt.schema)
+			if err == nil {
+				err = s.TheResponseShouldFailSchemaValidationWithError(tt.substring)
+			}
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeSemVerShouldSatisfy(t *testing.T) {
+	tests := []struct {
+		expr        string
+		constraints string
+		wantErr     bool
+	}{
+		{expr: "version", constraints: ">=1.2.0, <2.0.0"},
+		{expr: "version", constraints: ">=2.0.0", wantErr: true},
+		{expr: "$.dependencies[0].version", constraints: "<1.0.0"},
+		{expr: "invalid", constraints: ">=1.0.0", wantErr: true},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `{"version": "1.4.0", "invalid": "1.4", "dependencies": [{"version": "1.0.0-rc.1"}]}`))
+	for _, tt := range tests {
+		t.Run(tt.expr+" "+tt.constraints, func(t *testing.T) {
+			if err := s.TheNodeSemVerShouldSatisfy("JSON", tt.expr, tt.constraints); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldEqualExpression(t *testing.T) {
+	tests := []struct {
+		expr       string
+		arithmetic string
+		wantErr    bool
+	}{
+		{expr: "total", arithmetic: "{{.PRICE}} * {{.QTY}}"},
+		{expr: "total", arithmetic: "{{.PRICE}} * ({{.QTY}} + 1)", wantErr: true},
+		{expr: "$.items[0].net", arithmetic: "0.1 + 0.2"},
+		{expr: "name", arithmetic: "1", wantErr: true},
+		{expr: "total", arithmetic: "{{.PRICE}} / 0", wantErr: true},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("PRICE", 12.5)
+	s.APIContext.Cache.Save("QTY", 3)
+	sendGetRequest(t, s, bodyURL(srv, `{"total": 37.5, "name": "order", "items": [{"net": 0.3}]}`))
+	for _, tt := range tests {
+		t.Run(tt.expr+" "+tt.arithmetic, func(t *testing.T) {
+			if err := s.TheNodeShouldEqualExpression("JSON", tt.expr, tt.arithmetic); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeNumberShouldNotUseScientificNotation(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "id"},
+		{expr: "$.price"},
+		{expr: "big", wantErr: true},
+		{expr: "$.small", wantErr: true},
+		{expr: "name", wantErr: true},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `{"id": 100000, "price": 12.50, "big": 1e5, "small": 2.5E-3, "name": "x"}`))
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if err := s.TheNodeNumberShouldNotUseScientificNotation("JSON", tt.expr); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISaveLinkRelationURLAs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", `</users?page=2>; rel="next", <https://example.com/users?page=9>; rel="last"`)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{rel: "next", want: srv.URL + "/users?page=2"},
+		{rel: "LAST", want: "https://example.com/users?page=9"},
+		{rel: "prev", wantErr: true},
+	}
+
+	s := newTestScenario(t)
+	sendGetRequest(t, s, srv.URL+"/users?page=1")
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			if err := s.TheResponseLinkHeaderShouldHaveRelation(tt.rel); (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err := s.ISaveLinkRelationURLAs(tt.rel, "LINK_URL")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if saved, _ := s.APIContext.Cache.GetSaved("LINK_URL"); saved != tt.want {
+				t.Errorf("saved URL: want %s, got %v", tt.want, saved)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldEqualCachedBody(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "user"},
+		{expr: "$.copy"},
+		{expr: "other", wantErr: true},
+		{expr: "missing", wantErr: true},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `{"id": 1, "name": "x"}`))
+	if err := s.ISaveLastResponseBodyAs("USER_BODY"); err != nil {
+		t.Fatalf("could not save last response body, err: %v", err)
+	}
+
+	sendGetRequest(t, s, bodyURL(srv, `{"user": {"name": "x", "id": 1.0}, "copy": {"id": 1, "name": "x"}, "other": {"id": 2, "name": "x"}}`))
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if err := s.TheNodeShouldEqualCachedBody("JSON", tt.expr, "USER_BODY"); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyShouldContainJSON(t *testing.T) {
+	tests := []struct {
+		partial string
+		wantErr string
+	}{
+		{partial: `{"id": {{.USER_ID}}, "roles": ["admin"]}`},
+		{partial: `{"roles": ["user"]}`, wantErr: `$.roles[0]: expected "user", got "admin"`},
+		{partial: `{"id": `, wantErr: "expected partial JSON is invalid"},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("USER_ID", 7)
+	sendGetRequest(t, s, bodyURL(srv, `{"id": 7, "name": "x", "roles": ["admin", "user"]}`))
+	for _, tt := range tests {
+		t.Run(tt.partial, func(t *testing.T) {
+			err := s.TheResponseBodyShouldContainJSON(&godog.DocString{Content: tt.partial})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyShouldMatchShape(t *testing.T) {
+	tests := []struct {
+		shape   string
+		wantErr string
+	}{
+		{shape: `{"id": "int", "tags": ["string"], "owner": "any"}`},
+		{shape: `{"id": "string", "tags": ["string"], "owner": "any"}`, wantErr: "$.id: expected string, got int 7"},
+		{shape: `{"id": "number", "tags": ["string"], "owner": "any"}`, wantErr: "expected shape is invalid"},
+		{shape: `{"id": `, wantErr: "expected shape is not valid JSON"},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `{"id": 7, "tags": ["a", "b"], "owner": null}`))
+	for _, tt := range tests {
+		t.Run(tt.shape, func(t *testing.T) {
+			err := s.TheResponseBodyShouldMatchShape(&godog.DocString{Content: tt.shape})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldBeRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limited") == "true" {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	sendGetRequest(t, s, srv.URL+"?limited=true")
+	if err := s.TheResponseShouldBeRateLimited(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	before := time.Now()
+	if err := s.ISaveRateLimitResetTimeAs("RESET"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := s.APIContext.Cache.GetSaved("RESET")
+	if err != nil {
+		t.Fatalf("reset time should be saved, err: %v", err)
+	}
+
+	if reset, ok := saved.(time.Time); !ok || reset.Before(before.Add(30*time.Second)) || reset.After(time.Now().Add(30*time.Second)) {
+		t.Errorf("reset time should be about 30s from now, got: %v", saved)
+	}
+
+	sendGetRequest(t, s, srv.URL)
+	if err = s.TheResponseShouldBeRateLimited(); err == nil {
+		t.Errorf("response with status code 200 and without rate limit headers should not be rate limited")
+	}
+
+	if err = s.ISaveRateLimitResetTimeAs("RESET"); err == nil {
+		t.Errorf("reset time should not be obtained from response without rate limit headers")
+	}
+}
+
+func TestScenario_IPrepareNewRequestToUnixSocket(t *testing.T) {
+	socketPath := t.TempDir() + "/app.sock"
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("could not listen on Unix domain socket, err: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"host": %q, "path": %q}`, r.Host, r.URL.RequestURI())
+	}))
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("SOCKET", socketPath)
+	for _, socket := range []string{"{{.SOCKET}}", "unix://{{.SOCKET}}"} {
+		t.Run(socket, func(t *testing.T) {
+			if err := s.IPrepareNewRequestToUnixSocket(http.MethodGet, socket, "users?page=2", "SOCKET_REQUEST"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := s.ISendRequest("SOCKET_REQUEST"); err != nil {
+				t.Fatalf("could not send request to Unix domain socket, err: %v", err)
+			}
+
+			if err := s.TheResponseBodyShouldContainJSON(&godog.DocString{Content: `{"host": "localhost", "path": "/users?page=2"}`}); err != nil {
+				t.Errorf("unexpected response: %v", err)
+			}
+		})
+	}
+
+	if err = s.IPrepareNewRequestToUnixSocket(http.MethodGet, "unix://", "/users", "SOCKET_REQUEST"); err == nil {
+		t.Errorf("empty socket path should result in error")
+	}
+}
+
+func TestScenario_ISendPreparedRequestWithIdempotencyKeyTwiceAndAssertSameResult(t *testing.T) {
+	var created int32
+	var mu sync.Mutex
+	responses := map[string]string{}
+	newUser := func(w http.ResponseWriter, r *http.Request) string {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+
+		return fmt.Sprintf(`{"id": %d, "request": %s}`, atomic.AddInt32(&created, 1), body)
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "server honoring idempotency key",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				key := r.Header.Get("Idempotency-Key")
+				resp, ok := responses[key]
+				if !ok {
+					resp = newUser(w, r)
+					responses[key] = resp
+				} else {
+					w.WriteHeader(http.StatusCreated)
+				}
+
+				_, _ = w.Write([]byte(resp))
+			},
+		},
+		{
+			name: "server ignoring idempotency key",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(newUser(w, r)))
+			},
+			wantErr: "$.id: expected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodPost, srv.URL+"/users", "CREATE_USER")
+			if err := s.ISetFollowingBodyForPreparedRequest("CREATE_USER", &godog.DocString{Content: `{"name": "x"}`}); err != nil {
+				t.Fatalf("could not set body, err: %v", err)
+			}
+
+			err := s.ISendPreparedRequestWithIdempotencyKeyTwiceAndAssertSameResult("CREATE_USER", "Idempotency-Key")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+
+			if _, err = s.APIContext.Cache.GetSaved("CREATE_USER_IDEMPOTENCY_KEY"); err != nil {
+				t.Errorf("generated idempotency key should be saved, err: %v", err)
+			}
+
+			if err = s.TheResponseBodyShouldContainJSON(&godog.DocString{Content: `{"request": {"name": "x"}}`}); err != nil {
+				t.Errorf("request body should be sent both times, err: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldExistAndNotBeNull(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		wantErr     string
+		wantMissing bool
+	}{
+		{name: "not null node", expr: "name"},
+		{name: "null node", expr: "address", wantErr: "it should not be null"},
+		{name: "missing node", expr: "age", wantErr: "could not find node", wantMissing: true},
+		{name: "invalid expression", expr: "/user/[", wantErr: "could not find node"},
+	}
+
+	srv := newBodyServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, bodyURL(srv, `{"name": "John", "address": null}`))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.TheNodeShouldExistAndNotBeNull("JSON", tt.expr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+
+			if missing := strings.Contains(err.Error(), "it is missing"); missing != tt.wantMissing {
+				t.Errorf("node reported as missing: want %t, got %t, err: %v", tt.wantMissing, missing, err)
+			}
+		})
+	}
+}
+
+func TestScenario_IForceHTTP1AndHTTP2(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		force        func(s *Scenario) error
+		wantProtocol string
+		otherProto   string
+		serverProto  string
+	}{
+		{name: "HTTP/1.1", force: (*Scenario).IForceHTTP1, wantProtocol: "HTTP/1.1", otherProto: "HTTP/2", serverProto: "HTTP/1.1"},
+		{name: "HTTP/2", force: (*Scenario).IForceHTTP2, wantProtocol: "HTTP/2", otherProto: "HTTP/1.1", serverProto: "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			if err := tt.force(s); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			sendGetRequest(t, s, srv.URL)
+			if err := s.TheResponseProtocolShouldBe(tt.wantProtocol); err != nil {
+				t.Error(err)
+			}
+
+			if err := s.TheResponseProtocolShouldBe(tt.otherProto); err == nil {
+				t.Errorf("response protocol should not be %s", tt.otherProto)
+			}
+
+			if body, _ := s.APIContext.GetLastResponseBody(); string(body) != tt.serverProto {
+				t.Errorf("server should receive request using %s, got: %s", tt.serverProto, body)
+			}
+		})
+	}
+}
+
+func TestScenario_SendPreparedRequestForDurationAndAssertMinRPS(t *testing.T) {
+	var received int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		minRPS  float64
+		wantErr bool
+	}{
+		{name: "reachable minimum", minRPS: 10},
+		{name: "unreachable minimum", minRPS: 1e9, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&received, 0)
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodGet, srv.URL, "THROUGHPUT_REQUEST")
+
+			err := s.SendPreparedRequestForDurationAndAssertMinRPS("THROUGHPUT_REQUEST", "200ms", tt.minRPS)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			count, err := s.APIContext.Cache.GetSaved(LastThroughputRequestsCount)
+			if err != nil {
+				t.Fatalf("number of sent requests should be saved, err: %v", err)
+			}
+
+			if count != int(atomic.LoadInt32(&received)) || count.(int) < 2 {
+				t.Errorf("saved number of requests %v should be equal to number of requests received by server: %d", count, received)
+			}
+		})
+	}
+
+	s := newTestScenario(t)
+	prepareRequest(t, s, http.MethodGet, srv.URL, "THROUGHPUT_REQUEST")
+	if err := s.SendPreparedRequestForDurationAndAssertMinRPS("THROUGHPUT_REQUEST", "soon", 1); err == nil {
+		t.Errorf("invalid duration should result in error")
+	}
+}
+
+func TestScenario_ISendPreparedRequestReadingBodySlowly(t *testing.T) {
+	payload := strings.Repeat("x", 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	prepareRequest(t, s, http.MethodGet, srv.URL, "SLOW_REQUEST")
+	if err := s.ISendPreparedRequestReadingBodySlowly("SLOW_REQUEST", 0); err == nil {
+		t.Errorf("reading 0 bytes per second should not be possible")
+	}
+
+	start := time.Now()
+	if err := s.ISendPreparedRequestReadingBodySlowly("SLOW_REQUEST", 2000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 1000 bytes read at 2000 bytes per second take at least 500ms
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+		t.Errorf("reading body should be throttled, but it took %s", elapsed)
+	}
+
+	if err := s.APIContext.AssertTimeBetweenRequestAndResponseIs(450 * time.Millisecond); err == nil {
+		t.Errorf("measured time between request and response should include throttled reading of body")
+	}
+
+	if body, _ := s.APIContext.GetLastResponseBody(); string(body) != payload {
+		t.Errorf("whole body should be read, got %d bytes", len(body))
+	}
+}
+
+func TestScenario_ISetIfNoneMatchForPreparedRequest(t *testing.T) {
+	srv := newVersionedServer(t)
+	s := newTestScenario(t)
+	sendGetRequest(t, s, srv.URL)
+	if err := s.ISaveFromTheLastResponseHeaderAs("ETag", "ETAG"); err != nil {
+		t.Fatalf("could not save ETag, err: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		setUp      func(t *testing.T)
+		wantStatus int
+	}{
+		{name: "current ETag", setUp: func(t *testing.T) {}, wantStatus: http.StatusNotModified},
+		{name: "stale ETag", setUp: func(t *testing.T) {
+			prepareRequest(t, s, http.MethodPut, srv.URL, "UPDATE")
+			if err := s.ISendRequest("UPDATE"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+		}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setUp(t)
+			prepareRequest(t, s, http.MethodGet, srv.URL, "CONDITIONAL_GET")
+			if err := s.ISetIfNoneMatchForPreparedRequest("{{.ETAG}}", "CONDITIONAL_GET"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := s.ISendRequest("CONDITIONAL_GET"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			if err := s.TheResponseStatusCodeShouldOrShouldNotBe("", tt.wantStatus); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldHaveTrailer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		_, _ = w.Write([]byte(`{"id": 1}`))
+		w.Header().Set("X-Checksum", "abc123")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		trailer string
+		value   string
+		wantErr string
+	}{
+		{name: "present trailer", trailer: "X-Checksum", value: "{{.CHECKSUM}}"},
+		{name: "wrong value", trailer: "X-Checksum", value: "def456", wantErr: "is not equal to actual value: 'abc123'"},
+		{name: "missing trailer", trailer: "X-Signature", value: "abc123", wantErr: "available trailers: [X-Checksum]"},
+	}
+
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("CHECKSUM", "abc123")
+	sendGetRequest(t, s, srv.URL)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.TheResponseShouldHaveTrailer(tt.trailer, tt.value)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldBeChunked(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			for i := 0; i < 3; i++ {
+				_, _ = fmt.Fprintf(w, "{\"line\": %d}\n", i)
+				w.(http.Flusher).Flush()
+			}
+
+			return
+		}
+
+		body := []byte(`{"id": 1}`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/stream"},
+		{path: "/fixed", wantErr: true},
+	}
+
+	s := newTestScenario(t)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sendGetRequest(t, s, srv.URL+tt.path)
+			if err := s.TheResponseShouldBeChunked(); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseContentLengthShouldMatchBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := []byte(`{"id": 1}`)
+		if r.URL.Path == "/truncated" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)+10))
+		}
+
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{path: "/"},
+		{path: "/truncated", wantErr: "Content-Length is 19, but body size is 9 bytes"},
+	}
+
+	s := newTestScenario(t)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			sendGetRequest(t, s, srv.URL+tt.path)
+			err := s.TheResponseContentLengthShouldMatchBodySize()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISendPreparedRequestStreamingAndAssertMaxBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		body := []byte(strings.Repeat("x", size))
+		if r.URL.Query().Get("chunked") == "true" {
+			// flushing before body is written makes response chunked, without Content-Length
+			w.(http.Flusher).Flush()
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(size))
+		}
+
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		size    int
+		chunked bool
+		wantErr string
+	}{
+		{name: "Content-Length within limit", size: 100},
+		{name: "Content-Length equal to limit", size: 128},
+		{name: "Content-Length over limit", size: 129, wantErr: "but Content-Length is 129"},
+		{name: "chunked within limit", size: 128, chunked: true},
+		{name: "chunked over limit", size: 1000, chunked: true, wantErr: "reading was aborted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodGet, fmt.Sprintf("%s?size=%d&chunked=%t", srv.URL, tt.size, tt.chunked), "STREAMING")
+			err := s.ISendPreparedRequestStreamingAndAssertMaxBodySize("STREAMING", 128)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if body, _ := s.APIContext.GetLastResponseBody(); len(body) != tt.size {
+				t.Errorf("whole body of %d bytes should be saved, got %d bytes", tt.size, len(body))
+			}
+		})
+	}
+}
+
+func TestScenario_ISendPreparedRequestAcceptingEncodingAndAssert(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write(body)
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write(body)
+		_ = gz.Close()
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		acceptEncoding   string
+		expectedEncoding string
+		wantErr          bool
+	}{
+		{acceptEncoding: "gzip", expectedEncoding: "gzip"},
+		{acceptEncoding: "br, gzip;q=0.8", expectedEncoding: "GZIP"},
+		{acceptEncoding: "identity", expectedEncoding: "identity"},
+		{acceptEncoding: "br", expectedEncoding: "identity"},
+		{acceptEncoding: "br", expectedEncoding: "br", wantErr: true},
+		{acceptEncoding: "gzip", expectedEncoding: "identity", wantErr: true},
+	}
+
+	s := newTestScenario(t)
+	prepareRequest(t, s, http.MethodPost, srv.URL, "ENCODED")
+	if err := s.ISetFollowingBodyForPreparedRequest("ENCODED", &godog.DocString{Content: `{"name": "x"}`}); err != nil {
+		t.Fatalf("could not set body, err: %v", err)
+	}
+
+	// the same prepared request is sent many times, so its body should be sent every time
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding+" "+tt.expectedEncoding, func(t *testing.T) {
+			err := s.ISendPreparedRequestAcceptingEncodingAndAssert("ENCODED", tt.acceptEncoding, tt.expectedEncoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body, _ := s.APIContext.GetLastResponseBody()
+			if lastResp, _ := s.APIContext.GetLastResponse(); lastResp.Header.Get("Content-Encoding") == "gzip" {
+				gz, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatalf("response body should not be decompressed, err: %v", err)
+				}
+
+				body, _ = io.ReadAll(gz)
+			}
+
+			if string(body) != `{"name": "x"}` {
+				t.Errorf("request body should be sent, server echoed: %s", body)
+			}
+		})
+	}
+}
+
+func TestScenario_ICompressPreparedRequestBodyWithGzip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = io.Copy(w, gz)
+	}))
+	defer srv.Close()
+
+	body := `{"name": "` + strings.Repeat("x", 100) + `"}`
+	s := newTestScenario(t)
+	prepareRequest(t, s, http.MethodPost, srv.URL, "UPLOAD")
+	if err := s.ISetFollowingBodyForPreparedRequest("UPLOAD", &godog.DocString{Content: body}); err != nil {
+		t.Fatalf("could not set body, err: %v", err)
+	}
+
+	if err := s.ICompressPreparedRequestBodyWithGzip("UPLOAD"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	compressed, err := s.preparedRequestBody("UPLOAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(compressed) >= len(body) {
+		t.Errorf("compressed body should be smaller than original one, got %d bytes", len(compressed))
+	}
+
+	if err = s.ISendRequest("UPLOAD"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	if err = s.TheResponseStatusCodeShouldOrShouldNotBe("", http.StatusOK); err != nil {
+		t.Fatal(err)
+	}
+
+	if echoed, _ := s.APIContext.GetLastResponseBody(); string(echoed) != body {
+		t.Errorf("server should gunzip original body, got: %s", echoed)
+	}
+}
+
+func TestScenario_TheResponseCompressionRatioShouldBeAtLeast(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(`[` + strings.Repeat(`{"firstName": "John", "lastName": "Doe"},`, 100) + `{}]`))
+		_ = gz.Close()
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name           string
+		acceptEncoding bool
+		ratio          float64
+		wantErr        string
+	}{
+		{name: "passing ratio", acceptEncoding: true, ratio: 10},
+		{name: "failing ratio", acceptEncoding: true, ratio: 1000, wantErr: "compression ratio should be at least 1000.00"},
+		{name: "transparently decompressed response", ratio: 1, wantErr: "was decompressed by HTTP(s) client"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodGet, srv.URL, "COMPRESSED")
+			if tt.acceptEncoding {
+				if err := s.ISetFollowingHeadersForPreparedRequest("COMPRESSED", &godog.DocString{Content: `{"Accept-Encoding": "gzip"}`}); err != nil {
+					t.Fatalf("could not set headers, err: %v", err)
+				}
+			}
+
+			if err := s.ISendRequest("COMPRESSED"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			err := s.TheResponseCompressionRatioShouldBeAtLeast(tt.ratio)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISendCORSPreflightToWithOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowOrigin func(origin string) string
+		origin      string
+		wantErr     string
+	}{
+		{name: "permissive server", allowOrigin: func(string) string { return "*" }, origin: "https://app.example.com"},
+		{name: "server echoing origin", allowOrigin: func(origin string) string { return origin }, origin: "https://app.example.com"},
+		{
+			name:        "restrictive server",
+			allowOrigin: func(string) string { return "https://admin.example.com" },
+			origin:      "https://app.example.com",
+			wantErr:     "but Access-Control-Allow-Origin is: 'https://admin.example.com'",
+		},
+		{name: "server without CORS", allowOrigin: func(string) string { return "" }, origin: "https://app.example.com", wantErr: "does not have header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, requestMethod string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, requestMethod = r.Method, r.Header.Get("Access-Control-Request-Method")
+				if allowed := tt.allowOrigin(r.Header.Get("Origin")); allowed != "" {
+					w.Header().Set("Access-Control-Allow-Origin", allowed)
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			if err := s.ISendCORSPreflightToWithOrigin(srv.URL, tt.origin, "post"); err != nil {
+				t.Fatalf("could not send preflight request, err: %v", err)
+			}
+
+			if method != http.MethodOptions || requestMethod != http.MethodPost {
+				t.Errorf("server should receive OPTIONS request for POST method, got %s request for %s method", method, requestMethod)
+			}
+
+			err := s.TheResponseShouldAllowOrigin(tt.origin)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_IGenerateWeightedRandomChoiceAndSaveItAs(t *testing.T) {
+	t.Run("distribution", func(t *testing.T) {
+		s := newTestScenario(t)
+		doc := &godog.DocString{Content: "admin: 1\nuser: 3\nguest: 0.5\n"}
+		const runs = 9000
+		counts := map[string]int{}
+		for i := 0; i < runs; i++ {
+			if err := s.IGenerateWeightedRandomChoiceAndSaveItAs("ROLE", doc); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			role, err := s.APIContext.Cache.GetSaved("ROLE")
+			if err != nil {
+				t.Fatalf("could not obtain choice from cache, err: %v", err)
+			}
+
+			counts[role.(string)]++
+		}
+
+		for role, weight := range map[string]float64{"admin": 1, "user": 3, "guest": 0.5} {
+			want := runs * weight / 4.5
+			if got := float64(counts[role]); got < want*0.8 || got > want*1.2 {
+				t.Errorf("value %s should be chosen about %.0f times out of %d, got %.0f", role, want, runs, got)
+			}
+		}
+	})
+
+	tests := []struct {
+		name    string
+		choices string
+		wantErr string
+	}{
+		{name: "zero weight", choices: `{"admin": 0, "user": 1}`, wantErr: "weight of value 'admin' should be positive"},
+		{name: "negative weight", choices: "admin: -1\nuser: 1\n", wantErr: "weight of value 'admin' should be positive"},
+		{name: "not number weight", choices: `{"admin": "high"}`, wantErr: "could not deserialize choices"},
+		{name: "list instead of map", choices: "- admin\n- user\n", wantErr: "could not deserialize choices"},
+		{name: "no choices", choices: `{}`, wantErr: "no choices provided"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			err := s.IGenerateWeightedRandomChoiceAndSaveItAs("ROLE", &godog.DocString{Content: tt.choices})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_IWaitUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		until    time.Duration
+		wantWait time.Duration
+		wantLog  string
+	}{
+		{name: "near future", until: 300 * time.Millisecond, wantWait: 200 * time.Millisecond},
+		{name: "past", until: -time.Hour, wantLog: "has already passed, skipping waiting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			s := newTestScenario(t)
+			s.APIContext.SetDebugger(debugger.New(false, false, 1024, &output))
+
+			start := time.Now()
+			if err := s.IWaitUntil(start.Add(tt.until).Format(time.RFC3339Nano), time.RFC3339Nano); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if elapsed := time.Since(start); elapsed < tt.wantWait || elapsed > tt.wantWait+time.Second {
+				t.Errorf("should wait about %s, waited %s", tt.wantWait, elapsed)
+			}
+
+			if !strings.Contains(output.String(), tt.wantLog) {
+				t.Errorf("output should contain '%s', got: %s", tt.wantLog, output.String())
+			}
+		})
+	}
+
+	s := newTestScenario(t)
+	if err := s.IWaitUntil("tomorrow", time.RFC3339); err == nil || !strings.Contains(err.Error(), "could not parse time 'tomorrow'") {
+		t.Errorf("invalid time should not be accepted, got: %v", err)
+	}
+}
+
+func TestScenario_ThePreparedRequestBodyShouldBeValidJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "valid JSON", body: `{"name": "John", "tags": ["a", "b"]}`},
+		{name: "trailing comma", body: `{"name": "John", "tags": ["a", "b",]}`, wantErr: `near: ' "tags": ["a", "b",]}'`},
+		{name: "truncated JSON", body: `{"name": "John"`, wantErr: "body is not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodPost, "http://localhost", "CREATE")
+			if err := s.ISetFollowingBodyForPreparedRequest("CREATE", &godog.DocString{Content: tt.body}); err != nil {
+				t.Fatalf("could not set body, err: %v", err)
+			}
+
+			err := s.ThePreparedRequestBodyShouldBeValidJSON("CREATE")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_IURLEncodeCachedValueAndSaveAs(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "plain", want: "plain"},
+		{value: "John Doe & co", want: "John+Doe+%26+co"},
+		{value: "a/b?c=d#e", want: "a%2Fb%3Fc%3Dd%23e"},
+		{value: "50%+zażółć", want: "50%25%2Bza%C5%BC%C3%B3%C5%82%C4%87"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("RAW", tt.value)
+			if err := s.IURLEncodeCachedValueAndSaveAs("RAW", "ENCODED"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if encoded, _ := s.APIContext.Cache.GetSaved("ENCODED"); encoded != tt.want {
+				t.Errorf("want encoded %s, got %v", tt.want, encoded)
+			}
+
+			if err := s.IURLDecodeCachedValueAndSaveAs("ENCODED", "DECODED"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if decoded, _ := s.APIContext.Cache.GetSaved("DECODED"); decoded != tt.value {
+				t.Errorf("decoded value should equal original %s, got %v", tt.value, decoded)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeLengthShouldBe(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"tags": ["a", "b", "c"], "name": "Zoë", "owner": {"id": 1, "name": "x"}, "age": 30}`
+	tests := []struct {
+		name    string
+		expr    string
+		length  int
+		wantErr string
+	}{
+		{name: "array", expr: "tags", length: 3},
+		{name: "string counts characters, not bytes", expr: "name", length: 3},
+		{name: "object counts keys", expr: "owner", length: 2},
+		{name: "wrong length", expr: "tags", length: 2, wantErr: "node 'tags' is slice of length 3, expected length 2"},
+		{name: "number has no length", expr: "age", length: 2, wantErr: "should be string, slice or map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeLengthShouldBe("JSON", tt.expr, tt.length)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyShouldOrShouldNotMatchRegExp(t *testing.T) {
+	body := "id: 1\nname: John\nemail: john@example.com\nrole: admin\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		not     string
+		regExp  string
+		wantErr string
+	}{
+		{name: "anchored line in the middle of body", regExp: `^name: \w+$`},
+		{name: "many lines", regExp: `^email: \S+@example\.com\nrole: (admin|user)$`},
+		{name: "whole body anchored", regExp: `\Aid: \d+\n(.*\n){3}\z`},
+		{name: "anchor does not match within line", regExp: `^John$`, wantErr: "should match regExp '^John$', but it doesn't"},
+		{
+			name:    "mismatch far from beginning of body",
+			regExp:  `^email: \S+@example\.com\nrole: user$`,
+			wantErr: "regExp stops matching near: 'n@example.com\nrole: admin\n'",
+		},
+		{name: "not matching", not: "not", regExp: `^role: user$`},
+		{name: "unexpected match", not: "not", regExp: `^role: admin$`, wantErr: "should not match regExp '^role: admin$', but it matches"},
+		{name: "invalid regExp", regExp: `^role: (admin$`, wantErr: "could not compile regExp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyShouldOrShouldNotMatchRegExp(tt.not, tt.regExp)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheCachedValuesShouldBeEqual(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       any
+		b       any
+		wantErr string
+	}{
+		{name: "equal strings", a: "John", b: "John"},
+		{name: "equal maps", a: map[string]any{"id": 1.0}, b: map[string]any{"id": 1.0}},
+		{name: "different strings", a: "John", b: "Jane", wantErr: `cached values are not equal, 'A': "John" (string), 'B': "Jane" (string)`},
+		{name: "int and string of int", a: 1, b: "1", wantErr: `cached values are not equal, 'A': 1 (int), 'B': "1" (string)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("A", tt.a)
+			s.APIContext.Cache.Save("B", tt.b)
+
+			err := s.TheCachedValuesShouldBeEqual("A", "B")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("A", 1)
+	if err := s.TheCachedValuesShouldBeEqual("A", "MISSING"); err == nil || !strings.Contains(err.Error(), "key 'MISSING'") {
+		t.Errorf("missing cache key should be reported, got: %v", err)
+	}
+}
+
+func TestScenario_ISaveLastResponseStatusCodeAs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		code, _ := strconv.Atoi(r.URL.Query().Get("code"))
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	prepareRequest(t, s, http.MethodPost, srv.URL, "CREATE")
+	if err := s.ISendRequest("CREATE"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	if err := s.ISaveLastResponseStatusCodeAs("STATUS"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if status, _ := s.APIContext.Cache.GetSaved("STATUS"); status != http.StatusCreated {
+		t.Fatalf("cache should hold int %d, got %#v", http.StatusCreated, status)
+	}
+
+	// saved status code is used in template of next request
+	sendGetRequest(t, s, srv.URL+"?code={{.STATUS}}")
+	if err := s.TheResponseStatusCodeShouldOrShouldNotBe("", http.StatusCreated); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScenario_TheResponseETagShouldDifferFromCached(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  bool
+		wantErr string
+	}{
+		{name: "unchanged resource", wantErr: `last HTTP(s) response ETag "v1" should differ from cached 'ETAG' ETag "v1"`},
+		{name: "changed resource", update: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newVersionedServer(t)
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+			if err := s.ISaveFromTheLastResponseHeaderAs("ETag", "ETAG"); err != nil {
+				t.Fatalf("could not save ETag, err: %v", err)
+			}
+
+			if tt.update {
+				prepareRequest(t, s, http.MethodPut, srv.URL, "UPDATE")
+				if err := s.ISendRequest("UPDATE"); err != nil {
+					t.Fatalf("could not send request, err: %v", err)
+				}
+			}
+
+			sendGetRequest(t, s, srv.URL)
+			err := s.TheResponseETagShouldDifferFromCached("ETAG")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_IPrintDiffBetweenCachedJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		a          any
+		b          any
+		wantOutput []string
+	}{
+		{name: "equal JSON", a: `{"id": 1, "tags": ["a"]}`, b: []byte(`{"tags": ["a"], "id": 1}`), wantOutput: []string{"no differences between 'A' and 'B'"}},
+		{
+			name:       "different JSON",
+			a:          `{"id": 1, "owner": {"name": "John"}}`,
+			b:          map[string]any{"id": 2, "owner": map[string]any{"name": "Jane"}},
+			wantOutput: []string{"differences between 'A' (expected) and 'B' (actual)", "$.id: expected 1, got 2", `$.owner.name: expected "John", got "Jane"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			s := newTestScenario(t)
+			s.APIContext.SetDebugger(debugger.New(false, false, 1024, &output))
+			s.APIContext.Cache.Save("A", tt.a)
+			s.APIContext.Cache.Save("B", tt.b)
+
+			if err := s.IPrintDiffBetweenCachedJSON("A", "B"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output.String(), want) {
+					t.Errorf("output should contain '%s', got: %s", want, output.String())
+				}
+			}
+		})
+	}
+
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("A", `{"id": 1}`)
+	s.APIContext.Cache.Save("B", `{"id": `)
+	if err := s.IPrintDiffBetweenCachedJSON("A", "B"); err == nil || !strings.Contains(err.Error(), "value saved under key 'B' is not valid JSON") {
+		t.Errorf("invalid JSON should be reported, got: %v", err)
+	}
+}
+
+func TestScenario_TheResponseNodeShouldEqualSentBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id": 10, "echo": %s}`, body)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		body    string
+		expr    string
+		wantErr string
+	}{
+		{name: "echoed body", body: `{"name": "John", "tags": ["a", "b"]}`, expr: "echo"},
+		{name: "different node", body: `{"name": "John"}`, expr: "id", wantErr: `node 'id' is not equal to sent request body, differences:`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			if err := s.TheResponseNodeShouldEqualSentBody("JSON", tt.expr); err == nil {
+				t.Fatalf("sent body should not be known before any request is sent")
+			}
+
+			prepareRequest(t, s, http.MethodPost, srv.URL, "ECHO")
+			if err := s.ISetFollowingBodyForPreparedRequest("ECHO", &godog.DocString{Content: tt.body}); err != nil {
+				t.Fatalf("could not set body, err: %v", err)
+			}
+
+			if err := s.ISendRequest("ECHO"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			err := s.TheResponseNodeShouldEqualSentBody("JSON", tt.expr)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeSliceLengthShouldBeGreaterThanCached(t *testing.T) {
+	var mu sync.Mutex
+	var users []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPost {
+			users = append(users, fmt.Sprintf(`{"id": %d}`, len(users)+1))
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"users": [%s]}`, strings.Join(users, ","))
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	sendGetRequest(t, s, srv.URL)
+	if err := s.ISaveNodeSliceLengthAs("JSON", "users", "USERS_COUNT"); err != nil {
+		t.Fatalf("could not save slice length, err: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL)
+	if err := s.TheNodeSliceLengthShouldBeGreaterThanCached("JSON", "users", "USERS_COUNT"); err == nil || !strings.Contains(err.Error(), "node 'users' is slice of length 0, expected length greater than cached 'USERS_COUNT': 0") {
+		t.Errorf("list which did not grow should be reported, got: %v", err)
+	}
+
+	prepareRequest(t, s, http.MethodPost, srv.URL, "CREATE")
+	if err := s.ISendRequest("CREATE"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL)
+	if err := s.TheNodeSliceLengthShouldBeGreaterThanCached("JSON", "users", "USERS_COUNT"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	s.APIContext.Cache.Save("NOT_INT", "1")
+	if err := s.TheNodeSliceLengthShouldBeGreaterThanCached("JSON", "users", "NOT_INT"); err == nil || !strings.Contains(err.Error(), "should be int, got: string") {
+		t.Errorf("cached value which is not int should be reported, got: %v", err)
+	}
+}
+
+func TestScenario_TheResponseBodyShouldMatchTemplate(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"id": 17, "name": "John", "createdAt": "2023-03-01T12:00:00Z"}`
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{name: "templated fields", template: `{"id": {{.ID}}, "name": "John", "createdAt": "{{.CREATED_AT}}"}`},
+		{name: "whole body placeholder", template: `{{.BODY}}`},
+		{name: "literal mismatch", template: `{"id": {{.ID}}, "name": "Jane", "createdAt": "{{.CREATED_AT}}"}`, wantErr: `could not match literal text ", \"name\": \"Jane\", \"createdAt\": \""`},
+		{name: "unexpected text at the end", template: `{"id": {{.ID}}, "name": "John"`, wantErr: "unexpected text at the end of body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheResponseBodyShouldMatchTemplate(&godog.DocString{Content: tt.template})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISendPreparedRequestTwiceAndBodiesShouldBeByteIdentical(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/stable" {
+			_, _ = w.Write([]byte(`{"id": 1, "name": "John"}`))
+			return
+		}
+
+		// semantically equal JSON, but with different keys order
+		if atomic.AddInt32(&requests, 1)%2 == 1 {
+			_, _ = w.Write([]byte(`{"id": 1, "name": "John"}`))
+		} else {
+			_, _ = w.Write([]byte(`{"name": "John", "id": 1}`))
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "identical bodies", path: "/stable"},
+		{name: "reordered keys", path: "/reordered", wantErr: "response bodies differ at byte offset 2 (lengths: 25 and 25)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodGet, srv.URL+tt.path, "GET_USER")
+
+			err := s.ISendPreparedRequestTwiceAndBodiesShouldBeByteIdentical("GET_USER")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseVaryShouldInclude(t *testing.T) {
+	tests := []struct {
+		name       string
+		vary       []string
+		headerName string
+		wantErr    string
+	}{
+		{name: "first of many", vary: []string{"Accept-Encoding, Accept"}, headerName: "Accept-Encoding"},
+		{name: "last of many", vary: []string{"Accept-Encoding, Accept"}, headerName: "Accept"},
+		{name: "case insensitive", vary: []string{"Accept-Encoding, Accept"}, headerName: "accept"},
+		{name: "many header values", vary: []string{"Accept-Encoding", "Origin"}, headerName: "Origin"},
+		{name: "asterisk", vary: []string{"*"}, headerName: "Cookie"},
+		{name: "prefix of listed name", vary: []string{"Accept-Encoding, Accept"}, headerName: "Accept-Language", wantErr: "but it lists: [Accept-Encoding Accept]"},
+		{name: "no Vary header", headerName: "Accept", wantErr: "but it lists: []"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for _, vary := range tt.vary {
+					w.Header().Add("Vary", vary)
+				}
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseVaryShouldInclude(tt.headerName)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldBeBool(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"active": true, "deleted": false, "verified": "true"}`
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+		wantErr  string
+	}{
+		{name: "true", expr: "active", expected: "true"},
+		{name: "false", expr: "deleted", expected: "false"},
+		{name: "different value", expr: "active", expected: "false", wantErr: "node 'active' should be false, got: true"},
+		{name: "string true", expr: "verified", expected: "true", wantErr: `node 'verified' should be boolean true, got string "true"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeShouldBeBool("JSON", tt.expr, tt.expected)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISetHeadersFromFileForPreparedRequest(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	headersFile := filepath.Join(dir, "headers.yaml")
+	if err := os.WriteFile(headersFile, []byte("Authorization: Bearer {{.TOKEN}}\nX-Client: tests\n"), 0o600); err != nil {
+		t.Fatalf("could not write headers file, err: %v", err)
+	}
+
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("TOKEN", "abc123")
+	s.APIContext.Cache.Save("DIR", dir)
+	prepareRequest(t, s, http.MethodGet, srv.URL, "GET_USER")
+	if err := s.ISetHeadersFromFileForPreparedRequest("{{.DIR}}/headers.yaml", "GET_USER"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.ISendRequest("GET_USER"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	if header.Get("Authorization") != "Bearer abc123" || header.Get("X-Client") != "tests" {
+		t.Errorf("server should receive headers from file with template values replaced, got: %v", header)
+	}
+
+	if err := s.ISetHeadersFromFileForPreparedRequest(filepath.Join(dir, "missing.yaml"), "GET_USER"); err == nil || !strings.Contains(err.Error(), "could not read headers file") {
+		t.Errorf("missing file should be reported, got: %v", err)
+	}
+}
+
+func TestScenario_TheCachedJSONValueShouldHaveNode(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		expr    string
+		wantErr string
+	}{
+		{name: "JSON string", value: `{"user": {"id": 1}}`, expr: "user.id"},
+		{name: "JSON bytes", value: []byte(`{"users": [{"id": 1}]}`), expr: "users.0.id"},
+		{name: "deserialized data", value: map[string]any{"user": map[string]any{"id": 1}}, expr: "$.user.id"},
+		{name: "missing node", value: `{"user": {"id": 1}}`, expr: "user.email", wantErr: "cached JSON 'USER' does not have expected node"},
+		{name: "invalid JSON", value: `{"user": `, expr: "user", wantErr: "value saved under key 'USER' is not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("USER", tt.value)
+
+			err := s.TheCachedJSONValueShouldHaveNode("USER", tt.expr)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyLineCountShouldBe(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		n       int
+		wantErr string
+	}{
+		{name: "trailing new line", body: "a\nb\nc\n", n: 3},
+		{name: "no trailing new line", body: "a\nb\nc", n: 3},
+		{name: "empty lines count", body: "a\n\nc\n", n: 3},
+		{name: "single line", body: "a", n: 1},
+		{name: "empty body", body: "", n: 0},
+		{name: "wrong count", body: "a\nb\n", n: 3, wantErr: "last response body should have 3 lines, but has 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyLineCountShouldBe(tt.n)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeSignShouldBe(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"positive": 0.001, "negative": -5, "zero": 0, "negativeZero": -0.0, "name": "x"}`
+	tests := []struct {
+		name    string
+		expr    string
+		sign    string
+		wantErr string
+	}{
+		{name: "small positive", expr: "positive", sign: "positive"},
+		{name: "negative", expr: "negative", sign: "negative"},
+		{name: "zero", expr: "zero", sign: "zero"},
+		{name: "negative zero", expr: "negativeZero", sign: "zero"},
+		{name: "zero is non-negative", expr: "zero", sign: "non-negative"},
+		{name: "zero is non-positive", expr: "zero", sign: "non-positive"},
+		{name: "zero is not positive", expr: "zero", sign: "positive", wantErr: "node 'zero' should be positive, got: 0"},
+		{name: "zero is not negative", expr: "zero", sign: "negative", wantErr: "node 'zero' should be negative, got: 0"},
+		{name: "positive is not non-positive", expr: "positive", sign: "non-positive", wantErr: "should be non-positive"},
+		{name: "string", expr: "name", sign: "positive", wantErr: "node 'name' should be number, got: string"},
+		{name: "unknown sign", expr: "zero", sign: "neutral", wantErr: "unknown sign 'neutral'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeSignShouldBe("JSON", tt.expr, tt.sign)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldEqualNodeFromCachedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 7, "name": "John", "createdBy": 1}`))
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"user": {"id": %s, "name": "John", "createdBy": 2}}`, strings.TrimPrefix(r.URL.Path, "/users/"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		expr       string
+		cachedExpr string
+		wantErr    string
+	}{
+		{name: "id from create equals id from get", expr: "user.id", cachedExpr: "id"},
+		{name: "different nodes", expr: "user.createdBy", cachedExpr: "createdBy", wantErr: "node 'user.createdBy' is not equal to node 'createdBy' of cached response 'CREATED', differences:\n$: expected 1, got 2"},
+		{name: "missing cached node", expr: "user.id", cachedExpr: "email", wantErr: "problem with cached response 'CREATED'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodPost, srv.URL+"/users", "CREATE")
+			if err := s.ISendRequest("CREATE"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			if err := s.ISaveLastResponseAs("CREATED"); err != nil {
+				t.Fatalf("could not save response, err: %v", err)
+			}
+
+			if err := s.ISaveFromTheLastResponseNodeAs("JSON", "id", "USER_ID"); err != nil {
+				t.Fatalf("could not save node, err: %v", err)
+			}
+
+			sendGetRequest(t, s, srv.URL+"/users/{{.USER_ID}}")
+			err := s.TheNodeShouldEqualNodeFromCachedResponse("JSON", tt.expr, tt.cachedExpr, "CREATED")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISetBodyWithContentTypeForPreparedRequest(t *testing.T) {
+	var contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		contentType, body = r.Header.Get("Content-Type"), string(b)
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	s.APIContext.Cache.Save("NAME", "John")
+	s.APIContext.Cache.Save("VERSION", "2")
+	prepareRequest(t, s, http.MethodPost, srv.URL, "CREATE")
+	err := s.ISetBodyWithContentTypeForPreparedRequest("application/vnd.example.user.v{{.VERSION}}+json", "CREATE", &godog.DocString{Content: `{"name": "{{.NAME}}"}`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = s.ISendRequest("CREATE"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	if contentType != "application/vnd.example.user.v2+json" {
+		t.Errorf("server should receive vendor specific Content-Type, got: %s", contentType)
+	}
+
+	if body != `{"name": "John"}` {
+		t.Errorf("server should receive body with template values replaced, got: %s", body)
+	}
+}
+
+func TestScenario_TheLastRequestShouldHaveBeen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	if err := s.TheLastRequestShouldHaveBeen(http.MethodGet, srv.URL); err == nil || !strings.Contains(err.Error(), "no HTTP(s) request was sent yet") {
+		t.Fatalf("missing request should be reported, got: %v", err)
+	}
+
+	s.APIContext.Cache.Save("USER_ID", 7)
+	prepareRequest(t, s, http.MethodDelete, srv.URL+"/users/{{.USER_ID}}?force=true", "DELETE")
+	if err := s.ISendRequest("DELETE"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		wantErr string
+	}{
+		{name: "same method and URL", method: http.MethodDelete, url: srv.URL + "/users/{{.USER_ID}}?force=true"},
+		{name: "different method", method: http.MethodGet, url: srv.URL + "/users/7?force=true", wantErr: "last request should have been GET " + srv.URL + "/users/7?force=true, but was DELETE"},
+		{name: "different query", method: http.MethodDelete, url: srv.URL + "/users/7", wantErr: "but was DELETE " + srv.URL + "/users/7?force=true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.TheLastRequestShouldHaveBeen(tt.method, tt.url)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldBeInSchemaEnum(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"status": "active", "priority": 2, "role": "root"}`
+	schema := `{"type": "object", "properties": {"status": {"enum": ["active", "blocked"]}, "priority": {"enum": [1, 2, 3]}, "role": {"type": "string"}}}`
+
+	tests := []struct {
+		name    string
+		expr    string
+		pointer string
+		wantErr string
+	}{
+		{name: "string in enum", expr: "status", pointer: "/properties/status"},
+		{name: "number in enum", expr: "priority", pointer: "#/properties/priority"},
+		{name: "not in enum", expr: "role", pointer: "/properties/status", wantErr: `node 'role' should be one of ["active","blocked"], got: "root"`},
+		{name: "schema without enum", expr: "role", pointer: "/properties/role", wantErr: "'/properties/role' of schema 'user.json' does not have enum"},
+		{name: "missing pointer", expr: "role", pointer: "/properties/email", wantErr: "could not find '/properties/email' in schema 'user.json'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			if err := os.WriteFile(filepath.Join(s.JSONSchemaDir, "user.json"), []byte(schema), 0o600); err != nil {
+				t.Fatalf("could not write schema, err: %v", err)
+			}
+
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeShouldBeInSchemaEnum("JSON", tt.expr, tt.pointer, "user.json")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeSliceShouldBePermutationOfCached(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"ids": [3, 1, 2, 2], "users": [{"name": "Jane", "id": 2}, {"id": 1, "name": "John"}], "name": "x"}`
+	tests := []struct {
+		name    string
+		expr    string
+		cached  any
+		wantErr string
+	}{
+		{name: "reordered numbers", expr: "ids", cached: `[1, 2, 2, 3]`},
+		{name: "reordered objects", expr: "users", cached: []any{map[string]any{"id": 1, "name": "John"}, map[string]any{"id": 2, "name": "Jane"}}},
+		{name: "different number of occurrences", expr: "ids", cached: `[1, 2, 3, 3]`, wantErr: "missing elements: [3], extra elements: [2]"},
+		{name: "missing element", expr: "ids", cached: `[1, 2, 2, 3, 4]`, wantErr: "missing elements: [4], extra elements: []"},
+		{name: "node is not slice", expr: "name", cached: `["x"]`, wantErr: "node 'name' should be slice, got: string"},
+		{name: "cached value is not slice", expr: "ids", cached: `{"id": 1}`, wantErr: `value saved under key 'IDS' should be slice, got: {"id":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("IDS", tt.cached)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeSliceShouldBePermutationOfCached("JSON", tt.expr, "IDS")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldEchoRequestID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			w.Header().Set("X-Request-ID", r.Header.Get("X-Request-ID"))
+		case "/generate":
+			w.Header().Set("X-Request-ID", "generated-by-server")
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		requestID string
+		wantErr   string
+	}{
+		{name: "echoed request ID", path: "/echo", requestID: "req-123"},
+		{name: "different request ID", path: "/generate", requestID: "req-123", wantErr: "header 'X-Request-ID' should echo sent value 'req-123', but is 'generated-by-server'"},
+		{name: "missing response header", path: "/none", requestID: "req-123", wantErr: "should echo sent value 'req-123', but is ''"},
+		{name: "request without header", path: "/echo", wantErr: "last HTTP(s) request does not have header 'X-Request-ID'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodGet, srv.URL+tt.path, "GET_USER")
+			if tt.requestID != "" {
+				if err := s.ISetFollowingHeadersForPreparedRequest("GET_USER", &godog.DocString{Content: `{"X-Request-ID": "` + tt.requestID + `"}`}); err != nil {
+					t.Fatalf("could not set headers, err: %v", err)
+				}
+			}
+
+			if err := s.ISendRequest("GET_USER"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			err := s.TheResponseShouldEchoRequestID("X-Request-ID")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISetGeneratedIdempotencyKeyForPreparedRequest(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("Idempotency-Key")
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	prepareRequest(t, s, http.MethodPost, srv.URL, "CREATE_USER")
+	if err := s.ISetGeneratedIdempotencyKeyForPreparedRequest("Idempotency-Key", "CREATE_USER"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.ISendRequest("CREATE_USER"); err != nil {
+		t.Fatalf("could not send request, err: %v", err)
+	}
+
+	cached, err := s.APIContext.Cache.GetSaved("CREATE_USER_IDEMPOTENCY_KEY")
+	if err != nil {
+		t.Fatalf("generated key should be saved in cache, err: %v", err)
+	}
+
+	if received == "" || received != cached {
+		t.Errorf("server should receive the same key as saved in cache %v, got: '%s'", cached, received)
+	}
+
+	if _, err = uuid.FromString(received); err != nil {
+		t.Errorf("generated key should be UUID, got: %s", received)
+	}
+}
+
+func TestScenario_TheResponseBodyShouldBeValidUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr string
+	}{
+		{name: "ASCII", body: []byte(`{"name": "John"}`)},
+		{name: "multi-byte characters", body: []byte(`{"name": "Zażółć 日本"}`)},
+		{name: "Latin-1 encoded character", body: []byte("{\"name\": \"Jos\xe9\"}"), wantErr: "has invalid byte sequence at offset 13"},
+		{name: "truncated multi-byte character", body: []byte("abc\xe6\x97"), wantErr: "has invalid byte sequence at offset 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(tt.body)
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyShouldBeValidUTF8()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldHaveNodeAtPointer(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"user": {"addresses": [{"city": "Paris"}, {"city": "Rome"}]}, "a/b": 1, "m~n": 2, "": {"empty": true}}`
+	tests := []struct {
+		name    string
+		pointer string
+		wantErr string
+	}{
+		{name: "nested object and array", pointer: "/user/addresses/1/city"},
+		{name: "whole document", pointer: ""},
+		{name: "escaped slash", pointer: "/a~1b"},
+		{name: "escaped tilde", pointer: "/m~0n"},
+		{name: "empty key", pointer: "//empty"},
+		{name: "index out of range", pointer: "/user/addresses/2/city", wantErr: "should have node at pointer '/user/addresses/2/city'"},
+		{name: "unescaped slash", pointer: "/a/b", wantErr: "should have node at pointer '/a/b'"},
+		{name: "not pointer", pointer: "user.addresses", wantErr: "invalid JSON pointer 'user.addresses'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheResponseShouldHaveNodeAtPointer(tt.pointer)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldCloseConnection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/close" {
+			w.Header().Set("Connection", "close")
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "Connection: close", path: "/close"},
+		{name: "keep-alive", path: "/keep-alive", wantErr: "should close the connection, but it keeps it alive, Connection header: '', protocol: HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL+tt.path)
+
+			err := s.TheResponseShouldCloseConnection()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldBeJSONArrayOfLength(t *testing.T) {
+	srv := newBodyServer(t)
+	tests := []struct {
+		name    string
+		body    string
+		length  int
+		wantErr string
+	}{
+		{name: "root array", body: `[{"id": 1}, {"id": 2}]`, length: 2},
+		{name: "empty root array", body: `[]`, length: 0},
+		{name: "root object", body: `{"users": [{"id": 1}, {"id": 2}]}`, length: 2, wantErr: "last response body should be JSON array, got: object"},
+		{name: "length mismatch", body: `[1, 2, 3]`, length: 2, wantErr: "should be JSON array of length 2, got length: 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, tt.body))
+
+			err := s.TheResponseShouldBeJSONArrayOfLength(tt.length)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyChecksumShouldBe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		algorithm string
+		expected  string
+		wantErr   string
+	}{
+		{name: "sha256", algorithm: "sha256", expected: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+		{name: "upper case hex", algorithm: "sha256", expected: "B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9"},
+		{name: "md5", algorithm: "md5", expected: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{name: "different checksum", algorithm: "sha256", expected: "00", wantErr: "sha256 checksum should be 00, but is b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+		{name: "unknown algorithm", algorithm: "crc32", expected: "0d4a1185", wantErr: "unknown algorithm 'crc32'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyChecksumShouldBe(tt.algorithm, tt.expected)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_EachNodeSliceElementShouldHaveKeys(t *testing.T) {
+	srv := newBodyServer(t)
+	tests := []struct {
+		name    string
+		body    string
+		keys    string
+		wantErr string
+	}{
+		{name: "uniform elements", body: `{"users": [{"id": 1, "name": "John"}, {"id": 2, "name": "Jane", "age": 30}]}`, keys: "id, name"},
+		{name: "empty slice", body: `{"users": []}`, keys: "id, name"},
+		{
+			name:    "key missing partway",
+			body:    `{"users": [{"id": 1, "name": "John"}, {"id": 2, "name": "Jane"}, {"id": 3}, {"name": "Bob"}]}`,
+			keys:    "id, name",
+			wantErr: `element 2 of node 'users' is missing key 'name', element: {"id":3}`,
+		},
+		{name: "element is not object", body: `{"users": [{"id": 1, "name": "John"}, "Jane"]}`, keys: "id", wantErr: `element 1 of node 'users' should be object, got: "Jane"`},
+		{name: "node is not slice", body: `{"users": {"id": 1}}`, keys: "id", wantErr: "node 'users' should be slice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, tt.body))
+
+			err := s.EachNodeSliceElementShouldHaveKeys("JSON", "users", tt.keys)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldMatchFormat(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"id": 7, "login": "user-7", "ref": "user-7/John"}`
+	tests := []struct {
+		name    string
+		expr    string
+		format  string
+		argKeys string
+		wantErr string
+	}{
+		{name: "cached id as argument", expr: "login", format: "user-%v", argKeys: "USER_ID"},
+		{name: "cached id as template value", expr: "login", format: "user-{{.USER_ID}}"},
+		{name: "many arguments", expr: "ref", format: "user-%v/%s", argKeys: "USER_ID, NAME"},
+		{name: "number node", expr: "id", format: "%v", argKeys: "USER_ID"},
+		{name: "different value", expr: "login", format: "admin-%v", argKeys: "USER_ID", wantErr: "node 'login' should be 'admin-7', got: 'user-7'"},
+		{name: "missing argument", expr: "login", format: "user-%v", argKeys: "MISSING", wantErr: "could not obtain format argument"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+			if err := s.ISaveFromTheLastResponseNodeAs("JSON", "id", "USER_ID"); err != nil {
+				t.Fatalf("could not save node, err: %v", err)
+			}
+
+			s.APIContext.Cache.Save("NAME", "John")
+
+			err := s.TheNodeShouldMatchFormat("JSON", tt.expr, tt.format, tt.argKeys)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyShouldMatchRegExpNTimes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ERROR disk full\nINFO started\nERROR timeout\nERROR timeout\n"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		regExp  string
+		n       int
+		wantErr string
+	}{
+		{name: "no matches", regExp: `^FATAL`, n: 0},
+		{name: "single match", regExp: `^INFO \w+$`, n: 1},
+		{name: "many matches", regExp: `^ERROR`, n: 3},
+		{name: "non-overlapping matches", regExp: `ERROR timeout\nERROR`, n: 1},
+		{name: "wrong count", regExp: `timeout`, n: 1, wantErr: "last response body should match regExp 'timeout' 1 times, got: 2"},
+		{name: "invalid regExp", regExp: `(ERROR`, n: 1, wantErr: "could not compile regExp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyShouldMatchRegExpNTimes(tt.regExp, tt.n)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISetBodyFromCachedBytesForPreparedRequest(t *testing.T) {
+	var received []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	// bytes which are neither valid UTF-8 nor safe for template engine
+	raw := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, '{', '{', '.', 'X', '}', '}'}
+	tests := []struct {
+		name    string
+		cached  any
+		wantErr string
+	}{
+		{name: "bytes", cached: raw},
+		{name: "base64 encoded string", cached: base64.StdEncoding.EncodeToString(raw)},
+		{name: "invalid base64", cached: "not base64!", wantErr: "cached value 'FILE' is not valid base64 encoded string"},
+		{name: "other type", cached: 12, wantErr: "cached value 'FILE' should be []byte or base64 encoded string, got: int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("FILE", tt.cached)
+			prepareRequest(t, s, http.MethodPut, srv.URL, "UPLOAD")
+
+			err := s.ISetBodyFromCachedBytesForPreparedRequest("FILE", "UPLOAD")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err = s.ISendRequest("UPLOAD"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			if !bytes.Equal(received, raw) {
+				t.Errorf("server should receive bytes %X, got %X", raw, received)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseShouldValidateAgainstItsDeclaredSchema(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/schemas/user.json":
+			_, _ = w.Write([]byte(`{"type": "object", "required": ["$schema", "id"], "properties": {"id": {"type": "integer"}}}`))
+		case "/users/valid":
+			_, _ = w.Write([]byte(`{"$schema": "/schemas/user.json", "id": 1}`))
+		case "/users/invalid":
+			_, _ = w.Write([]byte(`{"$schema": "/schemas/user.json", "id": "1"}`))
+		case "/users/missing-schema":
+			_, _ = w.Write([]byte(`{"$schema": "/schemas/missing.json", "id": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "valid document", path: "/users/valid"},
+		{name: "invalid document", path: "/users/invalid", wantErr: "last response body is not valid according to its declared schema '" + srv.URL + "/schemas/user.json'"},
+		{name: "missing schema", path: "/users/missing-schema", wantErr: "could not fetch schema '" + srv.URL + "/schemas/missing.json'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL+tt.path)
+
+			err := s.TheResponseShouldValidateAgainstItsDeclaredSchema(`\$schema`)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheDifferenceBetweenNodesShouldBe(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"balanceBefore": 100.10, "balanceAfter": 75.05, "currency": "EUR"}`
+	tests := []struct {
+		name     string
+		exprA    string
+		exprB    string
+		expected float64
+		wantErr  string
+	}{
+		{name: "decrease", exprA: "balanceBefore", exprB: "balanceAfter", expected: 25.05},
+		{name: "negative difference", exprA: "balanceAfter", exprB: "balanceBefore", expected: -25.05},
+		{name: "wrong difference", exprA: "balanceBefore", exprB: "balanceAfter", expected: 25, wantErr: "difference between nodes 'balanceBefore' and 'balanceAfter' should be 25, got: 100.1 - 75.05"},
+		{name: "not number", exprA: "balanceBefore", exprB: "currency", expected: 0, wantErr: "node 'currency' should be number, got: string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheDifferenceBetweenNodesShouldBe("JSON", tt.exprA, tt.exprB, tt.expected)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeBoolShouldBeOppositeOfCached(t *testing.T) {
+	var mu sync.Mutex
+	active := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/toggle" {
+			active = !active
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"active": %t}`, active)
+	}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	sendGetRequest(t, s, srv.URL+"/status")
+	if err := s.ISaveNodeBoolAs("JSON", "active", "ACTIVE"); err != nil {
+		t.Fatalf("could not save node, err: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL+"/status")
+	if err := s.TheNodeBoolShouldBeOppositeOfCached("JSON", "active", "ACTIVE"); err == nil || !strings.Contains(err.Error(), "node 'active' should be false, opposite of cached 'ACTIVE' equal to true, got: true") {
+		t.Errorf("not toggled value should be reported, got: %v", err)
+	}
+
+	sendGetRequest(t, s, srv.URL+"/toggle")
+	if err := s.TheNodeBoolShouldBeOppositeOfCached("JSON", "active", "ACTIVE"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	s.APIContext.Cache.Save("NOT_BOOL", "true")
+	if err := s.TheNodeBoolShouldBeOppositeOfCached("JSON", "active", "NOT_BOOL"); err == nil || !strings.Contains(err.Error(), "cached value 'NOT_BOOL' should be boolean, got: string") {
+		t.Errorf("cached value which is not boolean should be reported, got: %v", err)
+	}
+}
+
+func TestScenario_IPrintLastResponseNode(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"user": {"name": "John", "address": {"city": "Paris", "tags": ["home"]}}}`
+	tests := []struct {
+		name       string
+		expr       string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "nested object",
+			expr:       "user.address",
+			wantOutput: "node 'user.address':\n{\n  \"city\": \"Paris\",\n  \"tags\": [\n    \"home\"\n  ]\n}",
+		},
+		{name: "string", expr: "user.name", wantOutput: "node 'user.name':\nJohn"},
+		{name: "missing node", expr: "user.email", wantErr: "node 'user.email' is missing in last response body (json)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			s := newTestScenario(t)
+			s.APIContext.SetDebugger(debugger.New(false, false, 1024, &output))
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.IPrintLastResponseNode(tt.expr)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+
+			if !strings.Contains(output.String(), tt.wantOutput) {
+				t.Errorf("output should contain '%s', got: %s", tt.wantOutput, output.String())
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldEqualTransformedCache(t *testing.T) {
+	srv := newBodyServer(t)
+	tests := []struct {
+		name      string
+		cached    string
+		body      string
+		transform string
+		wantErr   string
+	}{
+		{name: "upper", cached: "John Doe", body: `{"name": "JOHN DOE"}`, transform: "upper"},
+		{name: "lower", cached: "John Doe", body: `{"name": "john doe"}`, transform: "lower"},
+		{name: "trim", cached: " John Doe\n", body: `{"name": "John Doe"}`, transform: "trim"},
+		{name: "base64", cached: "John Doe", body: `{"name": "Sm9obiBEb2U="}`, transform: "base64"},
+		{name: "md5", cached: "John Doe", body: `{"name": "4c2a904bafba06591225113ad17b5cec"}`, transform: "md5"},
+		{name: "wrong transform", cached: "John Doe", body: `{"name": "JOHN DOE"}`, transform: "lower", wantErr: `node 'name' should be 'john doe' (cached 'NAME' transformed by lower), got: "JOHN DOE"`},
+		{name: "not string node", cached: "1", body: `{"name": 1}`, transform: "trim", wantErr: "got: 1"},
+		{name: "unknown transform", cached: "John Doe", body: `{"name": "John Doe"}`, transform: "sha1", wantErr: "unknown transform 'sha1'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("NAME", tt.cached)
+			sendGetRequest(t, s, bodyURL(srv, tt.body))
+
+			err := s.TheNodeShouldEqualTransformedCache("JSON", "name", "NAME", tt.transform)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyShouldBeValidHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "well-formed document", body: `<!DOCTYPE html><html><head><title>Users</title></head><body><ul><li><a href="/1">John</a></li></ul></body></html>`},
+		{name: "optional end tags", body: `<html><body><p>first<p>second<ul><li>a<li>b</ul><br><img src="x.png"></body></html>`},
+		{name: "not closed element", body: "<html><body>\n<div><span>John</div>\n</body></html>", wantErr: "found issues:\nline 2: element <span> not closed before </div>"},
+		{name: "empty document", body: ``, wantErr: "it is empty document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyShouldBeValidHTML()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNDJSONResponseShouldHaveLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		n       int
+		wantErr string
+	}{
+		{name: "clean stream", body: "{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}", n: 3},
+		{name: "trailing blank line", body: "{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}\n\n", n: 3},
+		{name: "CRLF line endings", body: "{\"id\": 1}\r\n{\"id\": 2}\r\n{\"id\": 3}\r\n", n: 3},
+		{name: "wrong count", body: "{\"id\": 1}\n{\"id\": 2}\n", n: 3, wantErr: "last response body should have 3 NDJSON objects, got: 2"},
+		{name: "invalid line", body: "{\"id\": 1}\n{\"id\": \n{\"id\": 3}\n", n: 3, wantErr: "last response body line 2 is not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheNDJSONResponseShouldHaveLines(tt.n)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeShouldBeMasked(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"card": "****1234", "password": "••••••••", "iban": "PL61109010140000071219812874", "pin": "XX12", "cvv": 123}`
+	tests := []struct {
+		name          string
+		expr          string
+		maskingRegExp *regexp.Regexp
+		wantErr       string
+	}{
+		{name: "masked with visible last digits", expr: "card"},
+		{name: "fully masked", expr: "password"},
+		{name: "unmasked value", expr: "iban", wantErr: "node 'iban' should be masked according to regExp"},
+		{name: "too many visible characters", expr: "card", maskingRegExp: regexp.MustCompile(`^\*+\d{0,2}$`), wantErr: "got: '****1234'"},
+		{name: "custom masking regExp", expr: "pin", maskingRegExp: regexp.MustCompile(`^X+\d{0,2}$`)},
+		{name: "not string", expr: "cvv", wantErr: "node 'cvv' should be masked string, got: 123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.MaskingRegExp = tt.maskingRegExp
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeShouldBeMasked("JSON", tt.expr)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TotalRequestsSentShouldBe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	s := newTestScenario(t)
+	if err := s.TotalRequestsSentShouldBe(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	prepareRequest(t, s, http.MethodGet, srv.URL, "GET_USERS")
+	for i := 0; i < 3; i++ {
+		if err := s.ISendRequest("GET_USERS"); err != nil {
+			t.Fatalf("could not send request, err: %v", err)
+		}
+	}
+
+	// preflight is counted as well
+	if err := s.ISendCORSPreflightToWithOrigin(srv.URL, "https://app.example.com", http.MethodGet); err != nil {
+		t.Fatalf("could not send preflight request, err: %v", err)
+	}
+
+	if err := s.TotalRequestsSentShouldBe(4); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := s.TotalRequestsSentShouldBe(3); err == nil || !strings.Contains(err.Error(), "should be 3, got: 4") {
+		t.Errorf("wrong number of requests should be reported, got: %v", err)
+	}
+
+	s.APIContext.SetRequestDoer(NewHTTPClient())
+	if err := s.TotalRequestsSentShouldBe(4); err == nil {
+		t.Errorf("requests should not be counted without tracing request doer")
+	}
+}
+
+// newTable returns godog table with given rows of cells.
+func newTable(rows ...[]string) *godog.Table {
+	table := &godog.Table{}
+	for _, cells := range rows {
+		row := &messages.PickleTableRow{}
+		for _, cell := range cells {
+			row.Cells = append(row.Cells, &messages.PickleTableCell{Value: cell})
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+func TestScenario_ISetBodyFromTableForPreparedRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   *godog.Table
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "key-value pairs with header",
+			table: newTable([]string{"key", "value"}, []string{"name", "{{.NAME}}"}, []string{"age", "30"}, []string{"zip", `"01234"`}),
+			want:  `{"age":30,"name":"John","zip":"01234"}`,
+		},
+		{
+			name:  "key-value pairs without header",
+			table: newTable([]string{"name", "John"}, []string{"active", "true"}, []string{"manager", "null"}),
+			want:  `{"active":true,"manager":null,"name":"John"}`,
+		},
+		{
+			name:  "two columns with other header are key-value pairs",
+			table: newTable([]string{"name", "age"}, []string{"John", "30"}),
+			want:  `{"John":30,"name":"age"}`,
+		},
+		{
+			name:  "header with many keys and single row",
+			table: newTable([]string{"name", "age", "active"}, []string{"John", "30", "true"}),
+			want:  `{"active":true,"age":30,"name":"John"}`,
+		},
+		{
+			name:  "header with many keys and many rows",
+			table: newTable([]string{"name", "age", "active"}, []string{"John", "30", "true"}, []string{"Jane", "25", "false"}),
+			want:  `[{"active":true,"age":30,"name":"John"},{"active":false,"age":25,"name":"Jane"}]`,
+		},
+		{name: "header without rows", table: newTable([]string{"name", "age", "active"}), wantErr: "should have at least one row with values"},
+		{name: "empty table", table: newTable(), wantErr: "table should have at least one row"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("NAME", "John")
+			prepareRequest(t, s, http.MethodPost, "http://localhost", "CREATE")
+
+			err := s.ISetBodyFromTableForPreparedRequest("CREATE", tt.table)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body, err := s.preparedRequestBody("CREATE")
+			if err != nil {
+				t.Fatalf("could not obtain prepared request body, err: %v", err)
+			}
+
+			if string(body) != tt.want {
+				t.Errorf("want body %s, got %s", tt.want, body)
+			}
+		})
+	}
+}
+
+func TestScenario_TheJSONNodesShouldBe(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"id": 1, "name": "John", "active": true, "address": {"city": "Paris"}}`
+	tests := []struct {
+		name       string
+		table      *godog.Table
+		wantErrs   []string
+		notWantErr string
+	}{
+		{
+			name:  "all nodes as expected",
+			table: newTable([]string{"path", "type", "value"}, []string{"id", "int", "1"}, []string{"name", "string", "John"}, []string{"address.city", "string", "Paris"}),
+		},
+		{
+			name:       "two fields fail",
+			table:      newTable([]string{"id", "int", "2"}, []string{"name", "string", "John"}, []string{"address.city", "string", "Rome"}),
+			wantErrs:   []string{"2 of JSON nodes are not as expected:", "\nrow 1: ", "\nrow 3: "},
+			notWantErr: "row 2",
+		},
+		{name: "wrong number of columns", table: newTable([]string{"id", "1"}), wantErrs: []string{"table row 1 should have 3 columns: path, type and value, got: 2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheJSONNodesShouldBe(tt.table)
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, wantErr := range tt.wantErrs {
+				if err == nil || !strings.Contains(err.Error(), wantErr) {
+					t.Errorf("error should contain '%s', got: %v", wantErr, err)
+				}
+			}
+
+			if tt.notWantErr != "" && err != nil && strings.Contains(err.Error(), tt.notWantErr) {
+				t.Errorf("error should not contain '%s', got: %v", tt.notWantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_IGenerateNextSequenceValueForAndSaveItAs(t *testing.T) {
+	s := newTestScenario(t)
+	steps := []struct {
+		sequence string
+		want     int
+	}{
+		{sequence: "users", want: 1},
+		{sequence: "users", want: 2},
+		{sequence: "orders", want: 1},
+		{sequence: "users", want: 3},
+		{sequence: "orders", want: 2},
+	}
+
+	for _, step := range steps {
+		if err := s.IGenerateNextSequenceValueForAndSaveItAs(step.sequence, "NEXT"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, _ := s.APIContext.Cache.GetSaved("NEXT"); got != step.want {
+			t.Errorf("sequence %s: want %d, got %v", step.sequence, step.want, got)
+		}
+	}
+
+	// every scenario has fresh cache, so sequences start anew
+	fresh := newTestScenario(t)
+	if err := fresh.IGenerateNextSequenceValueForAndSaveItAs("users", "NEXT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, _ := fresh.APIContext.Cache.GetSaved("NEXT"); got != 1 {
+		t.Errorf("sequence of new scenario should start from 1, got %v", got)
+	}
+
+	s.APIContext.Cache.Save(SequencesCacheKey, "broken")
+	if err := s.IGenerateNextSequenceValueForAndSaveItAs("users", "NEXT"); err == nil || !strings.Contains(err.Error(), "should be map[string]int, got: string") {
+		t.Errorf("invalid sequences value should be reported, got: %v", err)
+	}
+}
+
+func TestScenario_TheResponseHeaderIntShouldBeBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		min     int
+		max     int
+		wantErr string
+	}{
+		{name: "inside range", value: "42", min: 0, max: 100},
+		{name: "lower bound", value: "0", min: 0, max: 100},
+		{name: "upper bound", value: "100", min: 0, max: 100},
+		{name: "outside range", value: "101", min: 0, max: 100, wantErr: "header 'X-RateLimit-Remaining' should be integer between 0 and 100, got: 101"},
+		{name: "non-numeric", value: "many", min: 0, max: 100, wantErr: "header 'X-RateLimit-Remaining' should be integer, got: 'many'"},
+		{name: "missing header", min: 0, max: 100, wantErr: "does not have header 'X-RateLimit-Remaining'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.value != "" {
+					w.Header().Set("X-RateLimit-Remaining", tt.value)
+				}
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseHeaderIntShouldBeBetween("X-RateLimit-Remaining", tt.min, tt.max)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISaveFromTheLastResponseYAMLNodeAs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-yaml")
+		_, _ = w.Write([]byte("user:\n  name: John\n  address:\n    city: Paris\n  roles:\n    - admin\n    - user\nitems:\n  - id: 1\n    tags: [a, b]\n  - id: 2\n    tags: [c]\n"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    any
+		wantErr bool
+	}{
+		{name: "nested mapping", expr: "$.user.address.city", want: "Paris"},
+		{name: "sequence element", expr: "$.user.roles[1]", want: "user"},
+		{name: "mapping in sequence", expr: "$.items[1].id", want: uint64(2)},
+		{name: "sequence in mapping in sequence", expr: "$.items[0].tags[1]", want: "b"},
+		{name: "missing node", expr: "$.user.email", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.ISaveFromTheLastResponseYAMLNodeAs(tt.expr, "NODE")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got, _ := s.APIContext.Cache.GetSaved("NODE"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseDateHeaderShouldBeWithin(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		wantErr string
+	}{
+		{name: "fresh Date", date: time.Now().UTC().Format(http.TimeFormat)},
+		{name: "Date slightly in the future", date: time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)},
+		{name: "stale Date", date: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), wantErr: "last HTTP(s) response header 'Date' should be within 1m0s of now"},
+		{name: "not HTTP-date", date: "2023-03-01T12:00:00Z", wantErr: "should be valid HTTP-date, got: '2023-03-01T12:00:00Z'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", tt.date)
+			}))
+			defer srv.Close()
+
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseDateHeaderShouldBeWithin("1m")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_RequestShouldRequireHeader(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		switch r.Header.Get("Authorization") {
+		case "":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "Bearer valid":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "protected endpoint", token: "valid"},
+		{name: "invalid token", token: "invalid", wantErr: "request 'CREATE' should return status code 401 without header 'Authorization' and 201 with it, got: 401 without and 403 with"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies = nil
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("TOKEN", tt.token)
+			prepareRequest(t, s, http.MethodPost, srv.URL, "CREATE")
+			if err := s.ISetFollowingBodyForPreparedRequest("CREATE", &godog.DocString{Content: `{"name": "John"}`}); err != nil {
+				t.Fatalf("could not set body, err: %v", err)
+			}
+
+			err := s.RequestShouldRequireHeader("CREATE", "Authorization", "Bearer {{.TOKEN}}", http.StatusUnauthorized, http.StatusCreated)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+
+			if len(bodies) != 2 || bodies[0] != `{"name": "John"}` || bodies[1] != bodies[0] {
+				t.Errorf("both requests should have the same body, got: %q", bodies)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseBodyShouldStartWithBytes(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(png)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		hexPrefix string
+		wantErr   string
+	}{
+		{name: "PNG signature", hexPrefix: "89504E470D0A1A0A"},
+		{name: "spaces and lower case", hexPrefix: "89 50 4e 47"},
+		{name: "JPEG signature", hexPrefix: "FFD8FF", wantErr: "should start with bytes FFD8FF, but starts with: 89504E"},
+		{name: "prefix longer than body", hexPrefix: "89504E470D0A1A0A0000000000", wantErr: "(body length: 10)"},
+		{name: "invalid hex", hexPrefix: "PNG", wantErr: "'PNG' is not valid hex string"},
+		{name: "empty prefix", hexPrefix: " ", wantErr: "bytes prefix should not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, srv.URL)
+
+			err := s.TheResponseBodyShouldStartWithBytes(tt.hexPrefix)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeSliceElementsShouldAllBeType(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"names": ["John", "Jane"], "ids": [1, 2, 3], "prices": [1, 2.5], "users": [{"id": 1}, {"id": 2}], "mixed": ["John", 2, true], "empty": []}`
+	tests := []struct {
+		name    string
+		expr    string
+		goType  string
+		wantErr string
+	}{
+		{name: "strings", expr: "names", goType: "string"},
+		{name: "ints", expr: "ids", goType: "int"},
+		{name: "ints are floats", expr: "prices", goType: "float"},
+		{name: "maps", expr: "users", goType: "map"},
+		{name: "empty slice", expr: "empty", goType: "bool"},
+		{name: "float is not int", expr: "prices", goType: "int", wantErr: "every element of node 'prices' should be int, but element 1 is float: 2.5"},
+		{name: "heterogeneous", expr: "mixed", goType: "string", wantErr: "every element of node 'mixed' should be string, but element 1 is int: 2"},
+		{name: "not slice", expr: "names.0", goType: "string", wantErr: "node 'names.0' should be slice, got: string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeSliceElementsShouldAllBeType("JSON", tt.expr, tt.goType)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_ISaveFromCachedJSONNodeAs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cached  any
+		expr    string
+		want    any
+		wantErr string
+	}{
+		{name: "nested field of JSON string", cached: `{"user": {"address": {"city": "Paris"}}}`, expr: "user.address.city", want: "Paris"},
+		{name: "nested object of JSON bytes", cached: []byte(`{"user": {"address": {"city": "Paris"}}}`), expr: "user.address", want: map[string]any{"city": "Paris"}},
+		{name: "array element of deserialized data", cached: map[string]any{"users": []any{map[string]any{"id": 1.0}}}, expr: "$.users[0].id", want: float64(1)},
+		{name: "missing node", cached: `{"user": {}}`, expr: "user.address.city", wantErr: "problem with JSON saved under key 'USER'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("USER", tt.cached)
+
+			err := s.ISaveFromCachedJSONNodeAs("USER", tt.expr, "NODE")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got, _ := s.APIContext.Cache.GetSaved("NODE"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScenario_TimeBetweenCachedTimestampsShouldBeLessThan(t *testing.T) {
+	created := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		a       any
+		b       any
+		wantErr string
+	}{
+		{name: "RFC3339 strings", a: "2023-03-01T12:00:00Z", b: "2023-03-01T12:00:04.5Z"},
+		{name: "time values in reverse order", a: created.Add(4 * time.Second), b: created},
+		{name: "different time zones", a: "2023-03-01T12:00:00Z", b: "2023-03-01T14:00:03+02:00"},
+		{
+			name:    "difference equal to interval",
+			a:       created,
+			b:       "2023-03-01T12:00:05Z",
+			wantErr: "time between cached timestamps 'A' (2023-03-01T12:00:00Z) and 'B' (2023-03-01T12:00:05Z) should be less than 5s, got: 5s",
+		},
+		{name: "not timestamp", a: "yesterday", b: created, wantErr: "'A'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("A", tt.a)
+			s.APIContext.Cache.Save("B", tt.b)
+
+			err := s.TimeBetweenCachedTimestampsShouldBeLessThan("A", "B", "5s")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheResponseAllowHeaderShouldInclude(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPut {
+			w.Header().Add("Allow", "GET, HEAD")
+			w.Header().Add("Allow", "PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		method  string
+		wantErr string
+	}{
+		{name: "first method", method: http.MethodGet},
+		{name: "method from second header value", method: http.MethodPut},
+		{name: "not allowed method", method: http.MethodDelete, wantErr: "Allow header should include 'DELETE', but it lists: [GET HEAD PUT]"},
+		{name: "case sensitive", method: "get", wantErr: "Allow header should include 'get'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			prepareRequest(t, s, http.MethodPost, srv.URL, "CREATE")
+			if err := s.ISendRequest("CREATE"); err != nil {
+				t.Fatalf("could not send request, err: %v", err)
+			}
+
+			if err := s.TheResponseStatusCodeShouldOrShouldNotBe("", http.StatusMethodNotAllowed); err != nil {
+				t.Fatal(err)
+			}
+
+			err := s.TheResponseAllowHeaderShouldInclude(tt.method)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeBase64DecodedShouldEqual(t *testing.T) {
+	srv := newBodyServer(t)
+	body := `{"std": "Sm9obiBEb2U/Pg==", "rawURL": "Sm9obiBEb2U_Pg", "invalid": "Sm9obi*=", "id": 1}`
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+		wantErr  string
+	}{
+		{name: "standard encoding", expr: "std", expected: "John Doe?>"},
+		{name: "URL-safe encoding without padding", expr: "rawURL", expected: "{{.NAME}}?>"},
+		{name: "different content", expr: "std", expected: "Jane Doe?>", wantErr: "node 'std' base64 decoded should equal 'Jane Doe?>', got: 'John Doe?>'"},
+		{name: "invalid base64", expr: "invalid", expected: "John", wantErr: "node 'invalid' should be valid base64 encoded string, got: 'Sm9obi*='"},
+		{name: "not string", expr: "id", expected: "1", wantErr: "node 'id' should be base64 encoded string, got: 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			s.APIContext.Cache.Save("NAME", "John Doe")
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeBase64DecodedShouldEqual("JSON", tt.expr, tt.expected)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenario_TheNodeDateShouldBeInThe(t *testing.T) {
+	srv := newBodyServer(t)
+	body := fmt.Sprintf(`{"expiresAt": "%s", "createdAt": "%s", "birthday": "1990-05-17", "id": 1}`,
+		time.Now().Add(time.Hour).Format(time.RFC3339), time.Now().Add(-time.Hour).Format(time.RFC3339))
+	tests := []struct {
+		name    string
+		expr    string
+		layout  string
+		when    string
+		wantErr string
+	}{
+		{name: "future", expr: "expiresAt", layout: time.RFC3339, when: "future"},
+		{name: "past", expr: "createdAt", layout: time.RFC3339, when: "past"},
+		{name: "custom layout", expr: "birthday", layout: "2006-01-02", when: "past"},
+		{name: "past date is not in the future", expr: "createdAt", layout: time.RFC3339, when: "future", wantErr: "should be in the future"},
+		{name: "future date is not in the past", expr: "expiresAt", layout: time.RFC3339, when: "past", wantErr: "should be in the past"},
+		{name: "wrong layout", expr: "birthday", layout: time.RFC3339, when: "past", wantErr: "could not parse node 'birthday' value '1990-05-17'"},
+		{name: "not string", expr: "id", layout: time.RFC3339, when: "past", wantErr: "node 'id' should be string"},
+		{name: "unknown direction", expr: "expiresAt", layout: time.RFC3339, when: "present", wantErr: "unknown time direction 'present'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScenario(t)
+			sendGetRequest(t, s, bodyURL(srv, body))
+
+			err := s.TheNodeDateShouldBeInThe("JSON", tt.expr, tt.layout, tt.when)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error should contain '%s', got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
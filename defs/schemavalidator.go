@@ -0,0 +1,81 @@
+package defs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// DetailedSchemaReferenceValidator is entity that has ability to validate document against JSON schema
+// passed as reference. Unlike default validator, it reports every violation in separate line, together with
+// its JSON path and failing keyword. xeipuuv/gojsonschema is used under the hood.
+type DetailedSchemaReferenceValidator struct {
+	// SchemasDir is full OS path to directory with JSON schemas, relative references are resolved against it.
+	SchemasDir string
+}
+
+// NewDetailedSchemaReferenceValidator returns DetailedSchemaReferenceValidator.
+func NewDetailedSchemaReferenceValidator(schemasDir string) DetailedSchemaReferenceValidator {
+	return DetailedSchemaReferenceValidator{SchemasDir: schemasDir}
+}
+
+// Validate validates document against JSON schema available under reference,
+// which may be URL, full OS path or path relative to SchemasDir.
+func (v DetailedSchemaReferenceValidator) Validate(document, reference string) error {
+	source, err := schemaSource(v.SchemasDir, reference)
+	if err != nil {
+		return err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewReferenceLoader(source), gojsonschema.NewStringLoader(document))
+	if err != nil {
+		return err
+	}
+
+	return schemaValidationError(result)
+}
+
+// schemaSource returns source of JSON schema, that may be loaded by gojsonschema.NewReferenceLoader.
+// Reference may be URL, full OS path or path relative to schemasDir.
+func schemaSource(schemasDir, reference string) (string, error) {
+	if reference == "" {
+		return "", errors.New("provided schema reference should not be empty string")
+	}
+
+	if u, err := url.Parse(reference); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return reference, nil
+	}
+
+	source := reference
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(schemasDir, source)
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		return "", fmt.Errorf("%s isn't valid path to any resource on your OS, nor valid URL", reference)
+	}
+
+	return "file://" + source, nil
+}
+
+// schemaValidationError returns error listing every violation found during JSON schema validation,
+// each in separate line with JSON path and failing keyword, or nil if document is valid.
+func schemaValidationError(result *gojsonschema.Result) error {
+	if result.Valid() {
+		return nil
+	}
+
+	violations := make([]string, 0, len(result.Errors()))
+	for _, resultErr := range result.Errors() {
+		path := "$" + strings.TrimPrefix(resultErr.Context().String(), gojsonschema.STRING_CONTEXT_ROOT)
+		violations = append(violations, fmt.Sprintf("- %s [%s]: %s", path, resultErr.Type(), resultErr.Description()))
+	}
+
+	return fmt.Errorf("document is not valid according to JSON schema, found %d violation(s):\n%s",
+		len(violations), strings.Join(violations, "\n"))
+}
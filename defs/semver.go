@@ -0,0 +1,145 @@
+package defs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semVerRegExp matches semantic version as described in https://semver.org/spec/v2.0.0.html
+var semVerRegExp = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// semVer represents parsed semantic version. Build metadata is skipped, because it does not affect precedence.
+type semVer struct {
+	raw                 string
+	major, minor, patch uint64
+	preRelease          []string
+}
+
+// parseSemVer parses semantic version, for example: 1.2.3-beta.1+build.5
+func parseSemVer(version string) (semVer, error) {
+	matches := semVerRegExp.FindStringSubmatch(version)
+	if matches == nil {
+		return semVer{}, fmt.Errorf("'%s' is not valid semantic version", version)
+	}
+
+	v := semVer{raw: version}
+	for i, part := range []*uint64{&v.major, &v.minor, &v.patch} {
+		n, err := strconv.ParseUint(matches[i+1], 10, 64)
+		if err != nil {
+			return semVer{}, fmt.Errorf("'%s' is not valid semantic version, err: %w", version, err)
+		}
+
+		*part = n
+	}
+
+	if matches[4] != "" {
+		v.preRelease = strings.Split(matches[4], ".")
+	}
+
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 if v has lower, equal or higher precedence than other.
+func (v semVer) compare(other semVer) int {
+	for _, pair := range [][2]uint64{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	switch {
+	case len(v.preRelease) == 0 && len(other.preRelease) == 0:
+		return 0
+	case len(v.preRelease) == 0:
+		return 1
+	case len(other.preRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.preRelease) && i < len(other.preRelease); i++ {
+		if c := comparePreReleaseIdentifiers(v.preRelease[i], other.preRelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(v.preRelease) < len(other.preRelease):
+		return -1
+	case len(v.preRelease) > len(other.preRelease):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePreReleaseIdentifiers compares pre-release identifiers. Numeric identifiers are compared numerically
+// and have lower precedence than alphanumeric ones, which are compared lexically.
+func comparePreReleaseIdentifiers(a, b string) int {
+	numA, errA := strconv.ParseUint(a, 10, 64)
+	numB, errB := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case errA == nil && errB == nil:
+		if numA == numB {
+			return 0
+		}
+
+		if numA < numB {
+			return -1
+		}
+
+		return 1
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// satisfiesSemVerConstraints checks whether version satisfies all comma separated constraints,
+// for example: ">=1.2.0, <2.0.0". Available operators: =, !=, >, >=, <, <=. Missing operator means =.
+func satisfiesSemVerConstraints(version semVer, constraints string) (bool, error) {
+	for _, constraint := range strings.Split(constraints, ",") {
+		constraint = strings.TrimSpace(constraint)
+		operator := strings.TrimRight(constraint[:len(constraint)-len(strings.TrimLeft(constraint, "=!<>"))], " ")
+		expected, err := parseSemVer(strings.TrimSpace(constraint[len(operator):]))
+		if err != nil {
+			return false, fmt.Errorf("invalid constraint '%s', err: %w", constraint, err)
+		}
+
+		c := version.compare(expected)
+		var ok bool
+		switch operator {
+		case "", "=":
+			ok = c == 0
+		case "!=":
+			ok = c != 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		default:
+			return false, fmt.Errorf("invalid constraint '%s', unknown operator '%s', available: =, !=, >, >=, <, <=", constraint, operator)
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package defs
+
+import (
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		version string
+		valid   bool
+	}{
+		{version: "0.0.0", valid: true},
+		{version: "1.2.3", valid: true},
+		{version: "1.2.3-beta.1", valid: true},
+		{version: "1.2.3-0.3.7", valid: true},
+		{version: "1.2.3-x.7.z.92+build.5", valid: true},
+		{version: "1.2.3+20130313144700", valid: true},
+		{version: "1.2", valid: false},
+		{version: "v1.2.3", valid: false},
+		{version: "01.2.3", valid: false},
+		{version: "1.2.3-01", valid: false},
+		{version: "1.2.3-", valid: false},
+		{version: "1.2.3.4", valid: false},
+		{version: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			_, err := parseSemVer(tt.version)
+			if (err == nil) != tt.valid {
+				t.Errorf("valid: want %t, got error: %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	// versions in ascending precedence, as in example from https://semver.org/spec/v2.0.0.html#spec-item-11
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, err := parseSemVer(ordered[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := parseSemVer(ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+
+			if got := a.compare(b); got != want {
+				t.Errorf("compare(%s, %s): want %d, got %d", ordered[i], ordered[j], want, got)
+			}
+		}
+	}
+
+	a, _ := parseSemVer("1.0.0+build.1")
+	b, _ := parseSemVer("1.0.0+build.2")
+	if a.compare(b) != 0 {
+		t.Errorf("build metadata should not affect precedence")
+	}
+}
+
+func TestSatisfiesSemVerConstraints(t *testing.T) {
+	tests := []struct {
+		version     string
+		constraints string
+		want        bool
+		wantErr     bool
+	}{
+		{version: "1.2.3", constraints: "1.2.3", want: true},
+		{version: "1.2.3", constraints: "=1.2.3", want: true},
+		{version: "1.2.3", constraints: "!=1.2.3", want: false},
+		{version: "1.2.3", constraints: ">=1.2.0, <2.0.0", want: true},
+		{version: "2.0.0", constraints: ">=1.2.0, <2.0.0", want: false},
+		{version: "2.0.0-rc.1", constraints: "<2.0.0", want: true},
+		{version: "1.2.3", constraints: "> 1.2.3", want: false},
+		{version: "1.2.3", constraints: "<= 1.2.3", want: true},
+		{version: "1.2.3", constraints: ">=1.2", wantErr: true},
+		{version: "1.2.3", constraints: "=>1.2.3", wantErr: true},
+		{version: "1.2.3", constraints: ">=1.0.0,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraints, func(t *testing.T) {
+			version, err := parseSemVer(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := satisfiesSemVerConstraints(version, tt.constraints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
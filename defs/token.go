@@ -0,0 +1,96 @@
+package defs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pawelWritesCode/df"
+	"github.com/tidwall/gjson"
+)
+
+// rawNumberToken returns number exactly as it is written in data, without parsing it.
+// JSON expressions starting with "/" are not supported, because their library parses numbers right away.
+func rawNumberToken(dataFormat df.DataFormat, expr string, data []byte) (string, error) {
+	switch dataFormat {
+	case df.JSON:
+		return rawJSONNumberToken(expr, data)
+	case df.YAML:
+		return rawYAMLNumberToken(expr, data)
+	default:
+		return "", fmt.Errorf("raw tokens are not supported for data format '%s'", dataFormat)
+	}
+}
+
+// rawJSONNumberToken returns JSON number exactly as it is written in data.
+func rawJSONNumberToken(expr string, data []byte) (string, error) {
+	if len(expr) == 0 {
+		return "", errors.New("provided empty expression")
+	}
+
+	switch expr[0:1] {
+	case "$":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var decoded any
+		if err := decoder.Decode(&decoded); err != nil {
+			return "", fmt.Errorf("detected invalid JSON, err: %w", err)
+		}
+
+		node, err := jsonpath.JsonPathLookup(decoded, expr)
+		if err != nil {
+			return "", fmt.Errorf("could not find node using provided expression: '%s', err: %w", expr, err)
+		}
+
+		number, ok := node.(json.Number)
+		if !ok {
+			return "", fmt.Errorf("node '%s' should be number, got: %T", expr, node)
+		}
+
+		return number.String(), nil
+	case "/":
+		return "", fmt.Errorf("expression '%s' is not supported, use gjson or oliveagle/jsonpath syntax", expr)
+	default:
+		if !gjson.ValidBytes(data) {
+			return "", errors.New("detected invalid JSON")
+		}
+
+		result := gjson.GetBytes(data, expr)
+		if !result.Exists() {
+			return "", fmt.Errorf("could not find node using provided expression: '%s'", expr)
+		}
+
+		if result.Type != gjson.Number {
+			return "", fmt.Errorf("node '%s' should be number, got: %s", expr, result.Type)
+		}
+
+		return result.Raw, nil
+	}
+}
+
+// rawYAMLNumberToken returns YAML number exactly as it is written in data.
+func rawYAMLNumberToken(expr string, data []byte) (string, error) {
+	path, err := yaml.PathString(expr)
+	if err != nil {
+		return "", err
+	}
+
+	node, err := path.ReadNode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("could not find node using provided expression: '%s', err: %w", expr, err)
+	}
+
+	switch n := node.(type) {
+	case *ast.IntegerNode:
+		return n.Token.Value, nil
+	case *ast.FloatNode:
+		return n.Token.Value, nil
+	default:
+		return "", fmt.Errorf("node '%s' should be number, got: %s", expr, node.Type())
+	}
+}
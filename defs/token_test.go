@@ -0,0 +1,54 @@
+package defs
+
+import (
+	"testing"
+
+	"github.com/pawelWritesCode/df"
+)
+
+func TestRawNumberToken(t *testing.T) {
+	jsonData := []byte(`{"int": 100000, "sci": 1e5, "float": 1.50, "neg": -2E-3, "name": "x", "list": [{"v": 12.0}]}`)
+	yamlData := []byte("int: 100000\nsci: 1.0e+5\nfloat: 1.50\nname: x\nlist:\n  - v: 12.0\n")
+
+	tests := []struct {
+		name       string
+		dataFormat df.DataFormat
+		expr       string
+		data       []byte
+		want       string
+		wantErr    bool
+	}{
+		{name: "JSON gjson integer", dataFormat: df.JSON, expr: "int", data: jsonData, want: "100000"},
+		{name: "JSON gjson scientific", dataFormat: df.JSON, expr: "sci", data: jsonData, want: "1e5"},
+		{name: "JSON gjson trailing zero", dataFormat: df.JSON, expr: "float", data: jsonData, want: "1.50"},
+		{name: "JSON gjson nested", dataFormat: df.JSON, expr: "list.0.v", data: jsonData, want: "12.0"},
+		{name: "JSON jsonpath scientific", dataFormat: df.JSON, expr: "$.neg", data: jsonData, want: "-2E-3"},
+		{name: "JSON jsonpath nested", dataFormat: df.JSON, expr: "$.list[0].v", data: jsonData, want: "12.0"},
+		{name: "JSON not number", dataFormat: df.JSON, expr: "name", data: jsonData, wantErr: true},
+		{name: "JSON jsonpath not number", dataFormat: df.JSON, expr: "$.name", data: jsonData, wantErr: true},
+		{name: "JSON missing node", dataFormat: df.JSON, expr: "missing", data: jsonData, wantErr: true},
+		{name: "JSON pointer not supported", dataFormat: df.JSON, expr: "/int", data: jsonData, wantErr: true},
+		{name: "JSON empty expression", dataFormat: df.JSON, expr: "", data: jsonData, wantErr: true},
+		{name: "JSON invalid data", dataFormat: df.JSON, expr: "int", data: []byte(`{"int":`), wantErr: true},
+		{name: "YAML integer", dataFormat: df.YAML, expr: "$.int", data: yamlData, want: "100000"},
+		{name: "YAML scientific", dataFormat: df.YAML, expr: "$.sci", data: yamlData, want: "1.0e+5"},
+		{name: "YAML trailing zero", dataFormat: df.YAML, expr: "$.float", data: yamlData, want: "1.50"},
+		{name: "YAML nested", dataFormat: df.YAML, expr: "$.list[0].v", data: yamlData, want: "12.0"},
+		{name: "YAML not number", dataFormat: df.YAML, expr: "$.name", data: yamlData, wantErr: true},
+		{name: "YAML missing node", dataFormat: df.YAML, expr: "$.missing", data: yamlData, wantErr: true},
+		{name: "XML not supported", dataFormat: df.XML, expr: "//int", data: []byte(`<int>1</int>`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rawNumberToken(tt.dataFormat, tt.expr, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
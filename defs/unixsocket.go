@@ -0,0 +1,46 @@
+package defs
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// unixSocketHostSuffix marks URL host which holds hex encoded path to Unix domain socket.
+// Encoding socket path in host makes connections to different sockets use separate connection pools.
+const unixSocketHostSuffix = ".unix-socket"
+
+// unixSocketURL returns URL of resource available under path, served on Unix domain socket.
+// socketPath may be prefixed with unix:// scheme, for example: unix:///var/run/app.sock
+func unixSocketURL(socketPath, path string) (string, error) {
+	socketPath = strings.TrimPrefix(socketPath, "unix://")
+	if socketPath == "" {
+		return "", fmt.Errorf("path to Unix domain socket should not be empty string")
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return "http://" + hex.EncodeToString([]byte(socketPath)) + unixSocketHostSuffix + path, nil
+}
+
+// unixSocketDialContext wraps dial function, so it connects to Unix domain socket
+// if address was obtained from URL created by unixSocketURL. Other addresses are dialed unchanged.
+func unixSocketDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil || !strings.HasSuffix(host, unixSocketHostSuffix) {
+			return dial(ctx, network, addr)
+		}
+
+		socketPath, err := hex.DecodeString(strings.TrimSuffix(host, unixSocketHostSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("could not decode Unix domain socket path from host '%s', err: %w", host, err)
+		}
+
+		return dial(ctx, "unix", string(socketPath))
+	}
+}
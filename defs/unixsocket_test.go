@@ -0,0 +1,68 @@
+package defs
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestUnixSocketURL(t *testing.T) {
+	tests := []struct {
+		socketPath string
+		path       string
+		want       string
+		wantErr    bool
+	}{
+		{socketPath: "/var/run/app.sock", path: "/users", want: "http://2f7661722f72756e2f6170702e736f636b.unix-socket/users"},
+		{socketPath: "unix:///var/run/app.sock", path: "users?page=2", want: "http://2f7661722f72756e2f6170702e736f636b.unix-socket/users?page=2"},
+		{socketPath: "app.sock", path: "", want: "http://6170702e736f636b.unix-socket/"},
+		{socketPath: "unix://", path: "/users", wantErr: true},
+		{socketPath: "", path: "/users", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.socketPath+" "+tt.path, func(t *testing.T) {
+			got, err := unixSocketURL(tt.socketPath, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnixSocketDialContext(t *testing.T) {
+	tests := []struct {
+		addr        string
+		wantNetwork string
+		wantAddr    string
+		wantErr     bool
+	}{
+		{addr: "2f7661722f72756e2f6170702e736f636b.unix-socket:80", wantNetwork: "unix", wantAddr: "/var/run/app.sock"},
+		{addr: "localhost:1234", wantNetwork: "tcp", wantAddr: "localhost:1234"},
+		{addr: "localhost", wantNetwork: "tcp", wantAddr: "localhost"},
+		{addr: "not-hex.unix-socket:80", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			var network, addr string
+			dial := unixSocketDialContext(func(_ context.Context, n, a string) (net.Conn, error) {
+				network, addr = n, a
+
+				return nil, nil
+			})
+
+			if _, err := dial(context.Background(), "tcp", tt.addr); (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if network != tt.wantNetwork || addr != tt.wantAddr {
+				t.Errorf("want dial %s %s, got %s %s", tt.wantNetwork, tt.wantAddr, network, addr)
+			}
+		})
+	}
+}
@@ -50,6 +50,7 @@ Feature: Adding new user
     And the response should have header "Content-Type" of value "{{.CONTENT_TYPE_JSON}}; charset=UTF-8"
     And the response body should not have format "plain text"
     But the response body should have format "JSON"
+    And all JSON response keys should be "camelCase"
     And time between last request and response should be less than or equal to "2s"
 
     # uncommenting next line will print data to console
@@ -64,6 +65,7 @@ Feature: Adding new user
     # step argument may be: relative|full OS path, URL or raw schema definition
     # relativity is obtained through env variable GODOG_JSON_SCHEMA_DIR
     And the response body should be valid according to schema "user/response/user.json"
+    And the response body should be valid according to any of schemas "general_error.json, user/response/user.json"
     And the response body should be valid according to schema "{{.CWD}}/assets/test_server/doc/schema/user/response/user.json"
     And the response body should be valid according to schema "https://raw.githubusercontent.com/pawelWritesCode/godog-http-api/main/assets/test_server/doc/schema/user/response/user.json"
     And the response body should be valid according to schema:
@@ -108,6 +110,8 @@ Feature: Adding new user
 
     # date can be formatted according to one of available formats from standard go package "time"
     And the "JSON" node "friendSince" should be "string" of value "{{.MEET_DATE.Format `2006-01-02T15:04:05Z`}}"
+    # or parsed according to layout and compared with current time
+    And the "JSON" node "friendSince" in layout "2006-01-02T15:04:05Z" should be in the "past"
 
   Scenario: Successfully create user v2.
   As application user
@@ -193,4 +197,34 @@ Feature: Adding new user
     Then the response status code should not be 201
     But the response status code should be 400
     And the response body should have format "JSON"
-    And the response body should be valid according to schema "general_error.json"
\ No newline at end of file
+    And the response body should be valid according to schema "general_error.json"
+
+  Scenario: Created user violates multiple constraints of JSON schema
+  As application user
+  I would like to know about every violation of JSON schema
+  together with its JSON path.
+
+    #---------------------------------------------------------------------------------------------------
+    # Server accepts user without first name and under age, but schema "user/response/adult_user.json" does not.
+    When I send "POST" request to "{{.MY_APP_URL}}/users?format=json" with body and headers:
+    """
+    {
+        "body": {
+            "firstName": "",
+            "lastName": "doe-{{.RANDOM_LAST_NAME}}",
+            "age": 16,
+            "description": "{{.RANDOM_DESCRIPTION}}",
+            "friendSince": "{{.MEET_DATE.Format `2006-01-02T15:04:05Z`}}"
+        },
+        "headers": {
+            "Content-Type": "{{.CONTENT_TYPE_JSON}}"
+        }
+    }
+    """
+    Then the response status code should be 201
+    And the response body should be valid according to schema "user/response/user.json"
+    But the response body should not be valid according to schema "user/response/adult_user.json"
+    # every violation is reported with its JSON path and failing keyword
+    And the response body schema validation should fail with error containing "found 2 violation(s)"
+    And the response body schema validation should fail with error containing "$.firstName [string_gte]"
+    And the response body schema validation should fail with error containing "$.age [number_gte]"
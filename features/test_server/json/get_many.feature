@@ -86,4 +86,23 @@ Feature: Fetching many users.
     And the "JSON" node "@this" should be "slice"
     But the "JSON" node "@this" should not be slice of length "0"
     And the "JSON" node "@this" should not be "nil"
-    And the "JSON" node "@this" should not be "null"
\ No newline at end of file
+    And the "JSON" node "@this" should not be "null"
+
+  Scenario: Get users with retries on 5xx
+  As application user
+  I would like to fetch accounts
+  and retry request only if server responds with 5xx.
+
+    #---------------------------------------------------------------------------------------------------
+    # Healthy server responds at first attempt, so request is not retried.
+    Given I prepare new "GET" request to "{{.MY_APP_URL}}/users?format=json" and save it as "GET_USERS"
+    When I send request "GET_USERS" with 2 retries on 5xx backing off "100ms"
+    Then the response status code should be 200
+    And the response body should have format "JSON"
+    And the "JSON" node "@this" should be "slice"
+
+    #---------------------------------------------------------------------------------------------------
+    # Number of attempts is saved in cache under LAST_HTTP_REQUEST_ATTEMPTS key.
+    Given I save "{{.LAST_HTTP_REQUEST_ATTEMPTS}}" as "ATTEMPTS"
+    Given I save "1" as "EXPECTED_ATTEMPTS"
+    Then cached values "ATTEMPTS" and "EXPECTED_ATTEMPTS" should be equal
@@ -80,4 +80,88 @@ Feature: Fetching single user.
     Then the response status code should not be 200
     But the response status code should be 404
     And the response body should have format "JSON"
-    And the response body should be valid according to schema "general_error.json"
\ No newline at end of file
+    And the response body should be valid according to schema "general_error.json"
+
+  Scenario: Reuse header of response in following request
+  As application user
+  I would like to save header of response
+  and use its value later in scenario.
+
+    #---------------------------------------------------------------------------------------------------
+    # Create new user and save headers of response in scenario cache.
+    When I send "POST" request to "{{.MY_APP_URL}}/users?format=json" with body and headers:
+    """
+    {
+        "body": {
+            "firstName": "{{.RANDOM_FIRST_NAME}}",
+            "lastName": "{{.RANDOM_LAST_NAME}}",
+            "age": {{.RANDOM_AGE}},
+            "description": "{{.RANDOM_DESCRIPTION}}",
+            "friendSince": "{{.MEET_DATE.Format `2006-01-02T15:04:05Z`}}"
+        },
+        "headers": {
+            "Content-Type": "application/json"
+        }
+    }
+    """
+    Then the response status code should be 201
+    And I save from the last response header "Content-Type" as "USER_CONTENT_TYPE"
+    And I save from the last response header "Content-Length" as "USER_CONTENT_LENGTH"
+    And I save from the last response "JSON" node "id" as "USER_ID"
+
+    #---------------------------------------------------------------------------------------------------
+    # Saved header values are read back from scenario cache through template syntax.
+    Given I save "application/json; charset=UTF-8" as "EXPECTED_CONTENT_TYPE"
+    Then cached values "USER_CONTENT_TYPE" and "EXPECTED_CONTENT_TYPE" should be equal
+    When I send "GET" request to "{{.MY_APP_URL}}/users/{{.USER_ID}}?format=json" with body and headers:
+    """
+    {
+        "body": {},
+        "headers": {
+            "Accept": "{{.USER_CONTENT_TYPE}}"
+        }
+    }
+    """
+    Then the response status code should be 200
+    And the response should have header "Content-Type" of value "{{.USER_CONTENT_TYPE}}"
+    And the response should have header "Content-Length" of value "{{.USER_CONTENT_LENGTH}}"
+
+  Scenario: Compare node with arithmetic expression of cached values
+  As application user
+  I would like to check node value against arithmetic expression
+  built from values saved earlier in scenario.
+
+    #---------------------------------------------------------------------------------------------------
+    # Create new user with generated age and fetch it back.
+    Given I save "2" as "MULTIPLIER"
+    When I send "POST" request to "{{.MY_APP_URL}}/users?format=json" with body and headers:
+    """
+    {
+        "body": {
+            "firstName": "{{.RANDOM_FIRST_NAME}}",
+            "lastName": "{{.RANDOM_LAST_NAME}}",
+            "age": {{.RANDOM_AGE}},
+            "description": "{{.RANDOM_DESCRIPTION}}",
+            "friendSince": "{{.MEET_DATE.Format `2006-01-02T15:04:05Z`}}"
+        },
+        "headers": {
+            "Content-Type": "application/json"
+        }
+    }
+    """
+    Then the response status code should be 201
+    And I save from the last response "JSON" node "id" as "USER_ID"
+    When I send "GET" request to "{{.MY_APP_URL}}/users/{{.USER_ID}}?format=json" with body and headers:
+    """
+    {
+        "body": {},
+        "headers": {}
+    }
+    """
+    Then the response status code should be 200
+
+    #---------------------------------------------------------------------------------------------------
+    # Node "age" is compared with result of arithmetic expression evaluated from cached values.
+    And the "JSON" node "age" should equal expression "{{.RANDOM_AGE}} * {{.MULTIPLIER}} / {{.MULTIPLIER}}"
+    And the "JSON" node "age" should equal expression "({{.RANDOM_AGE}} - 18) + 18"
+    And the "JSON" node "id" should equal expression "{{.USER_ID}} + 0"
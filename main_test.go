@@ -58,7 +58,19 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 		If you would like to replace any of default state's utility services with your own, read:
 		https://pawelwritescode.github.io/godog-http-api.documentation/docs/utility-services/
 	*/
-	scenario := defs.Scenario{APIContext: gdutils.NewDefaultAPIContext(isDebug, path.Join(wd, os.Getenv(envJsonSchemaDir)))}
+	jsonSchemaDir := path.Join(wd, os.Getenv(envJsonSchemaDir))
+	scenario := defs.Scenario{APIContext: gdutils.NewDefaultAPIContext(isDebug, jsonSchemaDir), JSONSchemaDir: jsonSchemaDir}
+
+	// DeletableCache additionally allows removing single cache key, used by step `I remove cache key "([^"]*)"`.
+	scenario.APIContext.SetCache(defs.NewDeletableCache())
+
+	// DetailedSchemaReferenceValidator reports every JSON schema violation together with its JSON path and keyword.
+	scenario.APIContext.SetSchemaReferenceValidator(defs.NewDetailedSchemaReferenceValidator(jsonSchemaDir))
+
+	// TracingRequestDoer wraps HTTP client able to reach Unix domain sockets,
+	// so details about sent HTTP(s) requests, such as timings, are collected without polluting scenario cache.
+	// Its Logger, used after step `I enable structured request logging`, may be replaced with any defs.RequestLogger.
+	scenario.APIContext.SetRequestDoer(defs.NewTracingRequestDoer(defs.NewHTTPClient(), scenario.APIContext.Cache))
 
 	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 		scenario.APIContext.ResetState(isDebug)
@@ -70,6 +82,9 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 		return ctx, nil
 	})
 
+	// charsets is alternation of charsets names, available in steps generating random data or asserting on characters.
+	charsets := strings.Join(defs.CharsetNames, "|")
+
 	// Following declarations maps sentences to methods (define steps). To learn more on each step see
 	// https://pawelwritescode.github.io/godog-http-api.documentation/docs/steps-definitions/
 
@@ -84,14 +99,20 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   | - random length sentence of ASCII/UNICODE/polish/english/russian/japanese/emoji words,
 	   | - int/float from provided range,
 	   | - random bool value,
+	   | - random string matching provided regExp,
+	   | - random value picked from provided values according to their weights,
+	   | - next value of named sequence, starting from 1 in every scenario,
 	   | - time object moved forward/backward in time.
 	   |
 	   | Every method saves its output in scenario's cache under provided key for future use through text/template syntax.
 	*/
-	ctx.Step(`^I generate a random word having from "(\d+)" to "(\d+)" of "(ASCII|UNICODE|polish|english|russian|japanese|emoji)" characters and save it as "([^"]*)"$`, scenario.IGenerateARandomRunesOfLengthWithCharactersAndSaveItAs)
-	ctx.Step(`^I generate a random sentence having from "(\d+)" to "(\d+)" of "(ASCII|UNICODE|polish|english|russian|japanese|emoji)" words and save it as "([^"]*)"$`, scenario.IGenerateARandomSentenceInTheRangeFromToWordsAndSaveItAs(3, 10))
+	ctx.Step(`^I generate a random word having from "(\d+)" to "(\d+)" of "(`+charsets+`)" characters and save it as "([^"]*)"$`, scenario.IGenerateARandomRunesOfLengthWithCharactersAndSaveItAs)
+	ctx.Step(`^I generate a random sentence having from "(\d+)" to "(\d+)" of "(`+charsets+`)" words and save it as "([^"]*)"$`, scenario.IGenerateARandomSentenceInTheRangeFromToWordsAndSaveItAs(3, 10))
 	ctx.Step(`^I generate a random "(int|float)" in the range from "([^"]*)" to "([^"]*)" and save it as "([^"]*)"$`, scenario.IGenerateARandomNumberInTheRangeFromToAndSaveItAs)
 	ctx.Step(`^I generate a random bool value and save it as "([^"]*)"$`, scenario.IGenerateRandomBoolValueAndSaveItAs)
+	ctx.Step(`^I generate a string matching regExp "([^"]*)" and save it as "([^"]*)"$`, scenario.IGenerateStringMatchingRegExpAndSaveItAs)
+	ctx.Step(`^I pick a weighted random value and save it as "([^"]*)":$`, scenario.IGenerateWeightedRandomChoiceAndSaveItAs)
+	ctx.Step(`^I generate next value of sequence "([^"]*)" and save it as "([^"]*)"$`, scenario.IGenerateNextSequenceValueForAndSaveItAs)
 	ctx.Step(`^I generate current time and travel "(backward|forward)" "([^"]*)" in time and save it as "([^"]*)"$`, scenario.IGenerateCurrentTimeAndTravelByAndSaveItAs)
 
 	/*
@@ -109,21 +130,54 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   |
 	   | Second, more customisable:
 	   | 	step `^I prepare new "(GET|POST|PUT|PATCH|DELETE|HEAD)" request to ...`      - to prepare HTTP(s) request
+	   | 	step `^I prepare new "(GET|POST|PUT|PATCH|DELETE|HEAD)" request to unix ...` - to prepare request to Unix socket
 	   |	step `^I set following headers for prepared request "([^"]*)":$`             - setting headers (YAML|JSON)
+	   |	step `^I set headers from file "([^"]*)" for prepared request ...`           - setting headers from file (YAML|JSON)
 	   |	step `^I set following cookies for prepared request "([^"]*)":$`             - setting cookies (YAML|JSON)
 	   |	step `^I set following form for prepared request "([^"]*)":$`                - setting form (YAML|JSON)
 	   |	step `^I set following body for prepared request "([^"]*)":$`                - setting req body (any format)
+	   |	step `^I set following body with content type "([^"]*)" for prepared ...`    - setting req body and its Content-Type
+	   |	step `^I set body from cached bytes "([^"]*)" for prepared request ...`      - setting raw req body (bytes|base64)
+	   |	step `^I set body for prepared request "([^"]*)" from table:$`               - setting JSON req body from table
+	   |	step `^I gzip compress prepared request "([^"]*)" body$`                     - compressing req body (optional)
+	   |	step `^the prepared request "([^"]*)" body should be valid JSON$`            - checking req body (optional)
 	   |	step `^I send request "([^"]*)"$`                                            - to send prepared request
 	*/
 	ctx.Step(`^I prepare new "(GET|POST|PUT|PATCH|DELETE|HEAD)" request to "([^"]*)" and save it as "([^"]*)"$`, scenario.IPrepareNewRequestToAndSaveItAs)
+	ctx.Step(`^I prepare new "(GET|POST|PUT|PATCH|DELETE|HEAD)" request to unix socket "([^"]*)" path "([^"]*)" and save it as "([^"]*)"$`, scenario.IPrepareNewRequestToUnixSocket)
 	ctx.Step(`^I set following headers for prepared request "([^"]*)":$`, scenario.ISetFollowingHeadersForPreparedRequest)
+	ctx.Step(`^I set headers from file "([^"]*)" for prepared request "([^"]*)"$`, scenario.ISetHeadersFromFileForPreparedRequest)
+	ctx.Step(`^I set If-None-Match "([^"]*)" for prepared request "([^"]*)"$`, scenario.ISetIfNoneMatchForPreparedRequest)
+	ctx.Step(`^I set If-Modified-Since "([^"]*)" for prepared request "([^"]*)"$`, scenario.ISetIfModifiedSinceForPreparedRequest)
+	ctx.Step(`^I set a generated idempotency key header "([^"]*)" for prepared request "([^"]*)"$`, scenario.ISetGeneratedIdempotencyKeyForPreparedRequest)
 	ctx.Step(`^I set following cookies for prepared request "([^"]*)":$`, scenario.ISetFollowingCookiesForPreparedRequest)
 	ctx.Step(`^I set following form for prepared request "([^"]*)":$`, scenario.ISetFollowingFormForPreparedRequest)
 	ctx.Step(`^I set following body for prepared request "([^"]*)":$`, scenario.ISetFollowingBodyForPreparedRequest)
+	ctx.Step(`^I set following body with content type "([^"]*)" for prepared request "([^"]*)":$`, scenario.ISetBodyWithContentTypeForPreparedRequest)
+	ctx.Step(`^I set body from cached bytes "([^"]*)" for prepared request "([^"]*)"$`, scenario.ISetBodyFromCachedBytesForPreparedRequest)
+	ctx.Step(`^I set body for prepared request "([^"]*)" from table:$`, scenario.ISetBodyFromTableForPreparedRequest)
+	ctx.Step(`^I gzip compress prepared request "([^"]*)" body$`, scenario.ICompressPreparedRequestBodyWithGzip)
+	ctx.Step(`^the prepared request "([^"]*)" body should be valid JSON$`, scenario.ThePreparedRequestBodyShouldBeValidJSON)
 	ctx.Step(`^I send request "([^"]*)"$`, scenario.ISendRequest)
 
 	ctx.Step(`^I send "(GET|POST|PUT|PATCH|DELETE|HEAD)" request to "([^"]*)" with body and headers:$`, scenario.ISendRequestToWithBodyAndHeaders)
 
+	ctx.Step(`^I force HTTP/1.1$`, scenario.IForceHTTP1)
+	ctx.Step(`^I force HTTP/2$`, scenario.IForceHTTP2)
+	ctx.Step(`^I do not follow redirects$`, scenario.IDoNotFollowRedirects)
+	ctx.Step(`^I enable structured request logging$`, scenario.IEnableStructuredRequestLogging)
+	ctx.Step(`^I disable structured request logging$`, scenario.IDisableStructuredRequestLogging)
+
+	ctx.Step(`^I send CORS preflight to "([^"]*)" from origin "([^"]*)" for method "([^"]*)"$`, scenario.ISendCORSPreflightToWithOrigin)
+	ctx.Step(`^I send request "([^"]*)" for "([^"]*)" and throughput should be at least "([^"]*)" rps$`, scenario.SendPreparedRequestForDurationAndAssertMinRPS)
+	ctx.Step(`^I send request "([^"]*)" twice and bodies should be byte-identical$`, scenario.ISendPreparedRequestTwiceAndBodiesShouldBeByteIdentical)
+	ctx.Step(`^request "([^"]*)" should require header "([^"]*)" value "([^"]*)" returning (\d+) without and (\d+) with$`, scenario.RequestShouldRequireHeader)
+	ctx.Step(`^I send request "([^"]*)" twice with idempotency key header "([^"]*)" and results should match$`, scenario.ISendPreparedRequestWithIdempotencyKeyTwiceAndAssertSameResult)
+	ctx.Step(`^I send request "([^"]*)" with (\d+) retries on 5xx backing off "([^"]*)"$`, scenario.ISendPreparedRequestWithRetriesOn5xx)
+	ctx.Step(`^I send request "([^"]*)" streaming and abort if body exceeds (\d+) bytes$`, scenario.ISendPreparedRequestStreamingAndAssertMaxBodySize)
+	ctx.Step(`^I send request "([^"]*)" reading body at (\d+) bytes per second$`, scenario.ISendPreparedRequestReadingBodySlowly)
+	ctx.Step(`^I send request "([^"]*)" accepting encoding "([^"]*)" and response encoding should be "([^"]*)"$`, scenario.ISendPreparedRequestAcceptingEncodingAndAssert)
+
 	/*
 	   |----------------------------------------------------------------------------------------------------------------
 	   | Assertions
@@ -135,7 +189,8 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   | - HTTP(s) headers,
 	   | - HTTP(s) cookies,
 	   | - HTTP(s) status code,
-	   | - time between request - response.
+	   | - time between request - response,
+	   | - time to first byte of response, DNS lookup and connect time.
 	   |
 	   | Every argument following immediately after word "node" or "nodes"
 	   | should have syntax acceptable by one of path libraries and may contain template values:
@@ -147,13 +202,17 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   | Method "the response should have nodes" accepts list of nodes,
 	   | separated with comma ",". For example: "data.0.user, $.data.1.user, data".
 	   |
-	   | Argument in method starting with 'time between ...' should be string valid for
+	   | Argument in methods asserting on time, for example 'time between ...', should be string valid for
 	   | golang standard library time.ParseDuration func, for example: 3s, 1h, 30ms
 	   |
 	   | Most of the methods accepts template values in their arguments.
 	*/
 	ctx.Step(`^the response should (not )?have header "([^"]*)"$`, scenario.TheResponseShouldOrShouldNotHaveHeader)
 	ctx.Step(`^the response should have header "([^"]*)" of value "([^"]*)"$`, scenario.TheResponseShouldHaveHeaderOfValue)
+	ctx.Step(`^the response header "([^"]*)" should be an integer between (\d+) and (\d+)$`, scenario.TheResponseHeaderIntShouldBeBetween)
+	ctx.Step(`^the response Date header should be within "([^"]*)" of now$`, scenario.TheResponseDateHeaderShouldBeWithin)
+	ctx.Step(`^the response should be rate limited$`, scenario.TheResponseShouldBeRateLimited)
+	ctx.Step(`^the response ETag should differ from cached "([^"]*)"$`, scenario.TheResponseETagShouldDifferFromCached)
 
 	ctx.Step(`^the response should (not )?have cookie "([^"]*)"$`, scenario.TheResponseShouldOrShouldNotHaveCookie)
 	ctx.Step(`^the response should have cookie "([^"]*)" of value "([^"]*)"$`, scenario.TheResponseShouldHaveCookieOfValue)
@@ -161,23 +220,96 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 
 	ctx.Step(`^the response status code should (not )?be (\d+)$`, scenario.TheResponseStatusCodeShouldOrShouldNotBe)
 
+	ctx.Step(`^the response should echo request id header "([^"]*)"$`, scenario.TheResponseShouldEchoRequestID)
+	ctx.Step(`^the last request should have been "(GET|POST|PUT|PATCH|DELETE|HEAD)" to "([^"]*)"$`, scenario.TheLastRequestShouldHaveBeen)
+	ctx.Step(`^the response should allow origin "([^"]*)"$`, scenario.TheResponseShouldAllowOrigin)
+	ctx.Step(`^the response Location should be "([^"]*)"$`, scenario.TheResponseLocationShouldBe)
+	ctx.Step(`^the response Link header should have relation "([^"]*)"$`, scenario.TheResponseLinkHeaderShouldHaveRelation)
+	ctx.Step(`^the response Vary header should include "([^"]*)"$`, scenario.TheResponseVaryShouldInclude)
+	ctx.Step(`^the response Allow header should include "([^"]*)"$`, scenario.TheResponseAllowHeaderShouldInclude)
+	ctx.Step(`^the response should have trailer "([^"]*)" of value "([^"]*)"$`, scenario.TheResponseShouldHaveTrailer)
+	ctx.Step(`^the response compression ratio should be at least "([^"]*)"$`, scenario.TheResponseCompressionRatioShouldBeAtLeast)
+	ctx.Step(`^the response protocol should be "([^"]*)"$`, scenario.TheResponseProtocolShouldBe)
+	ctx.Step(`^the response should close the connection$`, scenario.TheResponseShouldCloseConnection)
+	ctx.Step(`^total requests sent should be (\d+)$`, scenario.TotalRequestsSentShouldBe)
+	ctx.Step(`^the response should be chunked$`, scenario.TheResponseShouldBeChunked)
+	ctx.Step(`^the response Content-Length should match the body size$`, scenario.TheResponseContentLengthShouldMatchBodySize)
+
 	ctx.Step(`^the "(JSON|YAML|XML|HTML)" response should have nodes "([^"]*)"$`, scenario.TheResponseShouldHaveNodes)
+	ctx.Step(`^the JSON response should have node at pointer "([^"]*)"$`, scenario.TheResponseShouldHaveNodeAtPointer)
+	ctx.Step(`^the JSON response should be an array of length (\d+)$`, scenario.TheResponseShouldBeJSONArrayOfLength)
+	ctx.Step(`^the JSON response should be an object$`, scenario.TheResponseShouldBeJSONObject)
+	ctx.Step(`^the response body should contain JSON:$`, scenario.TheResponseBodyShouldContainJSON)
+	ctx.Step(`^the response body should match shape:$`, scenario.TheResponseBodyShouldMatchShape)
+	ctx.Step(`^the NDJSON response should have (\d+) objects$`, scenario.TheNDJSONResponseShouldHaveLines)
 	ctx.Step(`^the "(JSON|YAML|XML|HTML)" response should (not )?have node "([^"]*)"$`, scenario.TheResponseShouldOrShouldNotHaveNode)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should not use scientific notation$`, scenario.TheNodeNumberShouldNotUseScientificNotation)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should be "(true|false)"$`, scenario.TheNodeShouldBeBool)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should be the opposite of cached "([^"]*)"$`, scenario.TheNodeBoolShouldBeOppositeOfCached)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should be "(positive|negative|zero|non-negative|non-positive)"$`, scenario.TheNodeSignShouldBe)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should be masked$`, scenario.TheNodeShouldBeMasked)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" base64-decoded should equal "([^"]*)"$`, scenario.TheNodeBase64DecodedShouldEqual)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should be a valid semantic version$`, scenario.TheNodeShouldBeSemVer)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should be a semantic version satisfying "([^"]*)"$`, scenario.TheNodeSemVerShouldSatisfy)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should be in enum at "([^"]*)" of schema "([^"]*)"$`, scenario.TheNodeShouldBeInSchemaEnum)
+	ctx.Step(`^the difference between "(JSON|YAML)" nodes "([^"]*)" and "([^"]*)" should be "([^"]*)"$`, scenario.TheDifferenceBetweenNodesShouldBe)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should match format "([^"]*)" with args "([^"]*)"$`, scenario.TheNodeShouldMatchFormat)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should equal expression "([^"]*)"$`, scenario.TheNodeShouldEqualExpression)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should exist and not be null$`, scenario.TheNodeShouldExistAndNotBeNull)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should be JSON null$`, scenario.TheNodeShouldBeJSONNull)
 
 	ctx.Step(`^the "(JSON|YAML|XML|HTML)" node "([^"]*)" should be "(bool|boolean|float|int|integer|number|scalar|string)" of value "([^"]*)"$`, scenario.TheNodeShouldBeOfValue)
+	ctx.Step(`^the JSON nodes should be:$`, scenario.TheJSONNodesShouldBe)
 	ctx.Step(`^the "(JSON|YAML|XML|HTML)" node "([^"]*)" should be "(bool|boolean|float|int|integer|number|scalar|string)" and contain one of values "([^"]*)"$`, scenario.TheNodeShouldBeOfValues)
 	ctx.Step(`^the "(JSON|YAML|XML|HTML)" node "([^"]*)" should (not )?contain sub string "([^"]*)"$`, scenario.TheNodeShouldOrShouldNotContainSubString)
 	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should (not )?be slice of length "(\d+)"$`, scenario.TheNodeShouldOrShouldNotBeSliceOfLength)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" length should be (\d+)$`, scenario.TheNodeLengthShouldBe)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" slice length should be greater than cached "([^"]*)"$`, scenario.TheNodeSliceLengthShouldBeGreaterThanCached)
 	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should (not )?be "(array|bool|boolean|float|int|integer|map|mapping|nil|null|number|object|sequence|scalar|slice|string)"$`, scenario.TheNodeShouldOrShouldNotBe)
 	ctx.Step(`^the "(JSON|YAML|XML|HTML)" node "([^"]*)" should (not )?match regExp "([^"]*)"$`, scenario.TheNodeShouldOrShouldNotMatchRegExp)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" should only contain "(`+charsets+`)" characters$`, scenario.TheNodeStringShouldBeInCharset)
+	ctx.Step(`^the "(JSON|YAML|XML)" node "([^"]*)" in layout "([^"]*)" should be in the "(past|future)"$`, scenario.TheNodeDateShouldBeInThe)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should equal cached body "([^"]*)"$`, scenario.TheNodeShouldEqualCachedBody)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should equal cached "([^"]*)" transformed by "(upper|lower|trim|base64|md5)"$`, scenario.TheNodeShouldEqualTransformedCache)
+	ctx.Step(`^each element of "(JSON|YAML)" node "([^"]*)" should have keys "([^"]*)"$`, scenario.EachNodeSliceElementShouldHaveKeys)
+	ctx.Step(`^all elements of "(JSON|YAML)" node "([^"]*)" should be "(string|int|float|bool|map|slice)"$`, scenario.TheNodeSliceElementsShouldAllBeType)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" slice should be a permutation of cached "([^"]*)"$`, scenario.TheNodeSliceShouldBePermutationOfCached)
+	ctx.Step(`^collected pagination items "([^"]*)" should have no duplicate "([^"]*)"$`, scenario.CollectedPaginationItemsShouldHaveNoDuplicates)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should equal node "([^"]*)" from cached response "([^"]*)"$`, scenario.TheNodeShouldEqualNodeFromCachedResponse)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should be absent compared to cached response "([^"]*)"$`, scenario.TheNodeShouldBeAbsentComparedToCachedResponse)
+	ctx.Step(`^the "(JSON|YAML)" node "([^"]*)" should equal the sent request body$`, scenario.TheResponseNodeShouldEqualSentBody)
 	ctx.Step(`^the "(JSON)" node "([^"]*)" should be valid according to schema "([^"]*)"$`, scenario.IValidateNodeWithSchemaReference)
 	ctx.Step(`^the "(JSON)" node "([^"]*)" should be valid according to schema:$`, scenario.IValidateNodeWithSchemaString)
 
 	ctx.Step(`^the response body should be valid according to schema "([^"]*)"$`, scenario.IValidateLastResponseBodyWithSchema)
 	ctx.Step(`^the response body should be valid according to schema:$`, scenario.IValidateLastResponseBodyWithFollowingSchema)
+	ctx.Step(`^the response body should not be valid according to schema "([^"]*)"$`, scenario.TheResponseShouldNotBeValidAccordingToSchema)
+	ctx.Step(`^the response body schema validation should fail with error containing "([^"]*)"$`, scenario.TheResponseShouldFailSchemaValidationWithError)
+	ctx.Step(`^the response body should validate against schema referenced at node "([^"]*)"$`, scenario.TheResponseShouldValidateAgainstItsDeclaredSchema)
+	ctx.Step(`^the response body should be valid according to any of schemas "([^"]*)"$`, scenario.TheResponseShouldBeValidAgainstAnyOf)
 	ctx.Step(`^the response body should (not )?have format "(JSON|YAML|XML|HTML|plain text)"$`, scenario.TheResponseBodyShouldOrShouldNotHaveFormat)
+	ctx.Step(`^the response body should (not )?match regExp "([^"]*)"$`, scenario.TheResponseBodyShouldOrShouldNotMatchRegExp)
+	ctx.Step(`^the response body should match regExp "([^"]*)" (\d+) times$`, scenario.TheResponseBodyShouldMatchRegExpNTimes)
+	ctx.Step(`^the response body "(sha256|sha1|md5)" checksum should be "([^"]*)"$`, scenario.TheResponseBodyChecksumShouldBe)
+	ctx.Step(`^the response body should be valid UTF-8$`, scenario.TheResponseBodyShouldBeValidUTF8)
+	ctx.Step(`^the response body should start with bytes "([^"]*)"$`, scenario.TheResponseBodyShouldStartWithBytes)
+	ctx.Step(`^the response body should be valid HTML$`, scenario.TheResponseBodyShouldBeValidHTML)
+	ctx.Step(`^the response HTML should have (\d+) elements matching "([^"]*)"$`, scenario.TheResponseHTMLShouldHaveElementsMatching)
+	ctx.Step(`^the response body should have (\d+) lines$`, scenario.TheResponseBodyLineCountShouldBe)
+	ctx.Step(`^the response body should have (less|more) than (\d+) lines$`, scenario.TheResponseBodyLineCountShouldBeLessOrMoreThan)
+	ctx.Step(`^the response body should match template:$`, scenario.TheResponseBodyShouldMatchTemplate)
+	ctx.Step(`^all JSON response keys should be "(camelCase|snake_case|PascalCase)"$`, scenario.TheResponseJSONKeysShouldBe)
+
+	ctx.Step(`^the cached JSON "([^"]*)" should have node "([^"]*)"$`, scenario.TheCachedJSONValueShouldHaveNode)
+	ctx.Step(`^cached values "([^"]*)" and "([^"]*)" should be equal$`, scenario.TheCachedValuesShouldBeEqual)
+	ctx.Step(`^the cached response "([^"]*)" status code should be (\d+)$`, scenario.TheCachedResponseStatusShouldBe)
 
 	ctx.Step(`^time between last request and response should be less than or equal to "([^"]*)"$`, scenario.TimeBetweenLastHTTPRequestResponseShouldBeLessThanOrEqualTo)
+	ctx.Step(`^time between cached timestamps "([^"]*)" and "([^"]*)" should be less than "([^"]*)"$`, scenario.TimeBetweenCachedTimestampsShouldBeLessThan)
+	ctx.Step(`^time to first byte should be less than "([^"]*)"$`, scenario.TimeToFirstByteShouldBeLessThan)
+	ctx.Step(`^DNS lookup time should be less than "([^"]*)"$`, scenario.DNSLookupTimeShouldBeLessThan)
+	ctx.Step(`^connect time should be less than "([^"]*)"$`, scenario.ConnectTimeShouldBeLessThan)
+	ctx.Step(`^the last request should have reused a connection$`, scenario.LastRequestShouldHaveReusedConnection)
 
 	/*
 	   |----------------------------------------------------------------------------------------------------------------
@@ -186,6 +318,11 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   |----------------------------------------------------------------------------------------------------------------
 	   |
 	   | This section contains method for preserving data in scenario cache
+	   | and transforming data already saved in it.
+	   |
+	   | Step `I collect items of the last response ... node ... into ...` appends elements of node slice
+	   | to items collected under given key, so items of consecutive pages of paginated endpoint may be accumulated
+	   | and checked afterwards using step `collected pagination items "([^"]*)" should have no duplicate "([^"]*)"`.
 	   |
 	   | Argument following immediately after word "node"
 	   | should have syntax acceptable by one of path libraries and may contain template values:
@@ -197,7 +334,21 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^I save "([^"]*)" as "([^"]*)"$`, scenario.ISaveAs)
 	ctx.Step(`^I save as "([^"]*)":$`, scenario.ISaveFollowingAs)
 	ctx.Step(`^I save from the last response "(JSON|YAML|XML|HTML)" node "([^"]*)" as "([^"]*)"$`, scenario.ISaveFromTheLastResponseNodeAs)
+	ctx.Step(`^I save from the last response YAML node "([^"]*)" as "([^"]*)"$`, scenario.ISaveFromTheLastResponseYAMLNodeAs)
+	ctx.Step(`^I save from cached JSON "([^"]*)" node "([^"]*)" as "([^"]*)"$`, scenario.ISaveFromCachedJSONNodeAs)
 	ctx.Step(`^I save from the last response header "([^"]*)" as "([^"]*)"$`, scenario.ISaveFromTheLastResponseHeaderAs)
+	ctx.Step(`^I save from the last response rate limit reset time as "([^"]*)"$`, scenario.ISaveRateLimitResetTimeAs)
+	ctx.Step(`^I save from the last response Link header relation "([^"]*)" URL as "([^"]*)"$`, scenario.ISaveLinkRelationURLAs)
+	ctx.Step(`^I save last response as "([^"]*)"$`, scenario.ISaveLastResponseAs)
+	ctx.Step(`^I save last response body as "([^"]*)"$`, scenario.ISaveLastResponseBodyAs)
+	ctx.Step(`^I save last response status code as "([^"]*)"$`, scenario.ISaveLastResponseStatusCodeAs)
+	ctx.Step(`^I save text of HTML element "([^"]*)" as "([^"]*)"$`, scenario.ISaveHTMLElementTextAs)
+	ctx.Step(`^I save "(JSON|YAML)" node "([^"]*)" bool as "([^"]*)"$`, scenario.ISaveNodeBoolAs)
+	ctx.Step(`^I save "(JSON|YAML|XML)" node "([^"]*)" slice length as "([^"]*)"$`, scenario.ISaveNodeSliceLengthAs)
+	ctx.Step(`^I collect items of the last response "(JSON|YAML)" node "([^"]*)" into "([^"]*)"$`, scenario.ICollectNodeItemsInto)
+	ctx.Step(`^I remove cache key "([^"]*)"$`, scenario.IRemoveCacheKey)
+	ctx.Step(`^I url encode "([^"]*)" and save it as "([^"]*)"$`, scenario.IURLEncodeCachedValueAndSaveAs)
+	ctx.Step(`^I url decode "([^"]*)" and save it as "([^"]*)"$`, scenario.IURLDecodeCachedValueAndSaveAs)
 
 	/*
 	   |----------------------------------------------------------------------------------------------------------------
@@ -209,7 +360,9 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   | This section contains methods that are useful for debugging during test creation phase.
 	*/
 	ctx.Step(`^I print last response body$`, scenario.IPrintLastResponseBody)
+	ctx.Step(`^I print last response node "([^"]*)"$`, scenario.IPrintLastResponseNode)
 	ctx.Step(`^I print cache data$`, scenario.IPrintCacheData)
+	ctx.Step(`^I print diff between JSON cached "([^"]*)" and "([^"]*)"$`, scenario.IPrintDiffBetweenCachedJSON)
 	ctx.Step(`^I start debug mode$`, scenario.IStartDebugMode)
 	ctx.Step(`^I stop debug mode$`, scenario.IStopDebugMode)
 
@@ -223,8 +376,12 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	   |
 	   | Argument in method 'I wait ([^"]*)"' should be string valid for
 	   | golang standard library time.ParseDuration func, for example: 3s, 1h, 30ms
+	   |
+	   | Arguments in method 'I wait until "([^"]*)" in layout "([^"]*)"' should be time and its layout
+	   | valid for golang standard library time.Parse func, for example: 2006-01-02T15:04:05Z07:00
 	*/
 	ctx.Step(`^I wait "([^"]*)"`, scenario.IWait)
+	ctx.Step(`^I wait until "([^"]*)" in layout "([^"]*)"$`, scenario.IWaitUntil)
 	ctx.Step(`^I stop scenario execution$`, scenario.IStopScenarioExecution)
 }
 